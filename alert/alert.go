@@ -0,0 +1,78 @@
+// Package alert detects when the usage of a billing period crosses configured thresholds.
+//
+// Every threshold fires at most once per billing period and account.
+// The alerts that have fired are recorded in a [State] that is persisted between runs.
+package alert
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/lmr-hh/easybell-billing-info/report"
+)
+
+// These constants identify the values that thresholds apply to.
+const (
+	// KindNational is the percentage of the national quota used so far.
+	KindNational = report.AlertNational
+	// KindMobile is the percentage of the mobile quota used so far.
+	KindMobile = report.AlertMobile
+	// KindCost is the projected additional cost at the end of the period.
+	KindCost = report.AlertCost
+)
+
+// Thresholds contains the limits above which alerts are fired.
+type Thresholds struct {
+	// Quotas are percentages of the national and the mobile quota, e.g. 80, 90 and 100.
+	Quotas []int
+	// Cost is the projected additional cost in Euro above which an alert is fired.
+	// Zero disables the cost alert.
+	Cost float64
+}
+
+// Validate checks that all thresholds are positive.
+func (t Thresholds) Validate() error {
+	var errs []error
+	for _, q := range t.Quotas {
+		if q <= 0 {
+			errs = append(errs, fmt.Errorf("invalid quota threshold %d: must be positive", q))
+		}
+	}
+	if t.Cost < 0 {
+		errs = append(errs, fmt.Errorf("invalid cost threshold %g: must not be negative", t.Cost))
+	}
+	return errors.Join(errs...)
+}
+
+// An Alert is a threshold that has been crossed.
+// It is attached to the report that is sent for it so that notifiers can show which threshold fired.
+type Alert = report.Alert
+
+// key identifies the threshold of a within a billing period, e.g. "national:90".
+func key(a Alert) string {
+	return a.Kind + ":" + strconv.FormatFloat(a.Threshold, 'f', -1, 64)
+}
+
+// Evaluate returns the alerts of all thresholds in t that are crossed by r.
+// Quota thresholds apply to the usage so far, the cost threshold applies to the projected cost of r.
+func (t Thresholds) Evaluate(r *report.Report) []Alert {
+	var alerts []Alert
+	quota := func(kind string, used, quota float64) {
+		if quota <= 0 {
+			return
+		}
+		percent := used / quota * 100
+		for _, q := range t.Quotas {
+			if percent >= float64(q) {
+				alerts = append(alerts, Alert{Kind: kind, Threshold: float64(q), Value: percent})
+			}
+		}
+	}
+	quota(KindNational, r.Usage.National.Seconds(), r.Tariff.NationalQuota.Seconds())
+	quota(KindMobile, r.Usage.Mobile.Seconds(), r.Tariff.MobileQuota.Seconds())
+	if cost := r.Cost(); t.Cost > 0 && cost > t.Cost {
+		alerts = append(alerts, Alert{Kind: KindCost, Threshold: t.Cost, Value: cost})
+	}
+	return alerts
+}
@@ -0,0 +1,110 @@
+package alert
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// State records the alerts that have been delivered in the current billing period of every account.
+// Alerts are recorded per notification target so that a failed target does not cause the other targets to receive an alert again.
+type State struct {
+	// Accounts maps account names to their period.
+	// If only a single account is configured, its name is empty.
+	Accounts map[string]*Period `json:"accounts"`
+}
+
+// Period contains the alerts that have been delivered in a billing period.
+type Period struct {
+	Start time.Time `json:"start"`
+	// Fired contains the keys of alerts that have been delivered to all targets.
+	// It is only written by earlier versions, which did not record the targets.
+	Fired []string `json:"fired,omitempty"`
+	// Targets maps the names of notification targets to the keys of the alerts that have been delivered to them.
+	Targets map[string][]string `json:"targets,omitempty"`
+}
+
+// Load reads the state from the file at path.
+// If the file does not exist, an empty state is returned.
+func Load(path string) (*State, error) {
+	s := &State{Accounts: make(map[string]*Period)}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(b, s); err != nil {
+		return nil, err
+	}
+	if s.Accounts == nil {
+		s.Accounts = make(map[string]*Period)
+	}
+	return s, nil
+}
+
+// Save writes s to the file at path.
+// The file is replaced atomically so that an interrupted run does not corrupt the state.
+func (s *State) Save(path string) error {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+	if err = os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(f.Name())
+	}()
+	if _, err = f.Write(append(b, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// New returns the alerts that have not been delivered to target in the period of account starting at start.
+func (s *State) New(account, target string, start time.Time, alerts []Alert) []Alert {
+	p := s.Accounts[account]
+	if p == nil || !p.Start.Equal(start) {
+		return alerts
+	}
+	var fresh []Alert
+	for _, a := range alerts {
+		if !slices.Contains(p.Fired, key(a)) && !slices.Contains(p.Targets[target], key(a)) {
+			fresh = append(fresh, a)
+		}
+	}
+	return fresh
+}
+
+// Record marks alerts as delivered to target in the period of account starting at start.
+// Alerts of previous periods are discarded.
+func (s *State) Record(account, target string, start time.Time, alerts []Alert) {
+	p := s.Accounts[account]
+	if p == nil || !p.Start.Equal(start) {
+		p = &Period{Start: start}
+		s.Accounts[account] = p
+	}
+	if p.Targets == nil {
+		p.Targets = make(map[string][]string)
+	}
+	for _, a := range alerts {
+		if !slices.Contains(p.Targets[target], key(a)) {
+			p.Targets[target] = append(p.Targets[target], key(a))
+		}
+	}
+}
@@ -0,0 +1,13 @@
+// Package cards contains the default Adaptive Card templates of the reports.
+//
+// The templates are bound to the data model of the notify package using Adaptive Card Templating.
+// month.json renders reports of completed billing periods, week.json reports of the current billing period
+// and summary.json combined reports of several accounts.
+package cards
+
+import "embed"
+
+// FS contains the templates.
+//
+//go:embed *.json
+var FS embed.FS
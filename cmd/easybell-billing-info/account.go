@@ -0,0 +1,194 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lmr-hh/easybell-billing-info/easybell"
+	"github.com/lmr-hh/easybell-billing-info/notify"
+	"github.com/lmr-hh/easybell-billing-info/report"
+)
+
+// An account is an easyBell account with its own tariff and notification targets.
+type account struct {
+	// Name identifies the account in reports.
+	// If only a single account is configured, the name is empty.
+	Name          string
+	Username      string
+	Password      string
+	Tariff        report.Tariff
+	Notifications notifications
+	// Notifier sends the reports of the account to all enabled notification targets.
+	Notifier notify.Multi
+	// Targets contains the names of the notification targets in the order of Notifier.
+	Targets []string
+	// client is the authenticated client of the account if sessions are kept.
+	client *easybell.Client
+}
+
+// keepSessions indicates that accounts keep their authenticated client between calls of forEachAccount.
+var keepSessions bool
+
+// accounts contains all configured accounts.
+var accounts []*account
+
+// globalTariff returns the tariff configured via flags, environment variables and the global section of the configuration file.
+func globalTariff() report.Tariff {
+	return report.Tariff{
+		NationalQuota:       NationalQuota,
+		MobileQuota:         MobileQuota,
+		NationalMinutePrice: NationalMinutePrice,
+		MobileMinutePrice:   MobileMinutePrice,
+	}
+}
+
+// mergeTariff returns t with the values that are set in c replaced.
+func mergeTariff(t report.Tariff, c tariffConfig) report.Tariff {
+	if c.NationalMinutes != nil {
+		t.NationalQuota = *c.NationalMinutes
+	}
+	if c.MobileMinutes != nil {
+		t.MobileQuota = *c.MobileMinutes
+	}
+	if c.NationalPrice != nil {
+		t.NationalMinutePrice = *c.NationalPrice
+	}
+	if c.MobilePrice != nil {
+		t.MobileMinutePrice = *c.MobilePrice
+	}
+	return t
+}
+
+// loadAccounts initializes accounts from the configuration file and the global settings.
+// If the configuration file does not define any accounts, a single account is created from the global settings.
+func loadAccounts(file *config) (errs []error) {
+	global := globalNotifications(file.Notifications)
+	if len(file.Accounts) == 0 {
+		a := &account{Tariff: globalTariff(), Notifications: global}
+		var err error
+		if a.Username, err = lookupSecretEnv("EASYBELL_USERNAME"); err != nil {
+			errs = append(errs, err)
+		} else if a.Username == "" {
+			if a.Username = file.Credentials.Username; a.Username == "" {
+				errs = append(errs, errors.New("no username specified"))
+			}
+		}
+		if a.Password, err = lookupSecretEnv("EASYBELL_PASSWORD"); err != nil {
+			errs = append(errs, err)
+		} else if a.Password == "" {
+			if a.Password = file.Credentials.Password; a.Password == "" {
+				errs = append(errs, errors.New("no password specified"))
+			}
+		}
+		accounts = []*account{a}
+	} else {
+		accounts = make([]*account, len(file.Accounts))
+		for i, c := range file.Accounts {
+			a := &account{
+				Name:          c.Name,
+				Username:      c.Credentials.Username,
+				Password:      c.Credentials.Password,
+				Tariff:        mergeTariff(globalTariff(), c.Tariff),
+				Notifications: global.merge(c.Notifications),
+			}
+			if a.Username == "" {
+				errs = append(errs, a.wrap(errors.New("no username specified")))
+			}
+			if a.Password == "" {
+				errs = append(errs, a.wrap(errors.New("no password specified")))
+			}
+			accounts[i] = a
+		}
+	}
+
+	for _, a := range accounts {
+		if a.Tariff.NationalQuota == 0 {
+			errs = append(errs, a.wrap(errors.New("no national minutes specified")))
+		}
+		if a.Tariff.MobileQuota == 0 {
+			errs = append(errs, a.wrap(errors.New("no mobile minutes specified")))
+		}
+		for _, err := range a.Notifications.validate() {
+			errs = append(errs, a.wrap(err))
+		}
+		a.Notifier, a.Targets = a.Notifications.notifiers()
+	}
+	return errs
+}
+
+// wrap annotates err with the name of a.
+func (a *account) wrap(err error) error {
+	if err == nil || a.Name == "" {
+		return err
+	}
+	return fmt.Errorf("account %s: %w", a.Name, err)
+}
+
+// newReport returns a report of kind for the usage of a in the period between start and end.
+func (a *account) newReport(kind report.Kind, start, end time.Time, usage easybell.Usage) *report.Report {
+	return &report.Report{
+		Kind:    kind,
+		Account: a.Name,
+		Start:   start,
+		End:     end,
+		Usage:   usage,
+		Tariff:  a.Tariff,
+	}
+}
+
+// session returns an authenticated client for a.
+// If sessions are kept, the client is reused until an error occurs.
+// reused reports whether the client was logged in by an earlier call.
+func (a *account) session() (client *easybell.Client, reused bool, err error) {
+	if a.client != nil {
+		return a.client, true, nil
+	}
+	client = easybell.NewClient()
+	if err = client.Login(a.Username, a.Password); err != nil {
+		return nil, false, err
+	}
+	if keepSessions {
+		a.client = client
+	}
+	return client, false, nil
+}
+
+// sessionMu serializes calls of forEachAccount so that the kept sessions are not used concurrently.
+var sessionMu sync.Mutex
+
+// forEachAccount logs in to every account concurrently and calls f with an authenticated client.
+// If f fails because a kept session has expired, f is called once more after logging in again,
+// so f must not have side effects.
+// The results and errors are returned in the order of accounts.
+// The errors are annotated with the name of the respective account.
+func forEachAccount[T any](f func(a *account, client *easybell.Client) (T, error)) ([]T, []error) {
+	sessionMu.Lock()
+	defer sessionMu.Unlock()
+	results := make([]T, len(accounts))
+	errs := make([]error, len(accounts))
+	var wg sync.WaitGroup
+	for i, a := range accounts {
+		wg.Go(func() {
+			client, reused, err := a.session()
+			if err == nil {
+				results[i], err = f(a, client)
+			}
+			if reused && errors.Is(err, easybell.ErrNotAuthenticated) {
+				// The kept session has expired, so log in again and retry once.
+				a.client = nil
+				if client, _, err = a.session(); err == nil {
+					results[i], err = f(a, client)
+				}
+			}
+			if err != nil {
+				// The session may be broken, so the next call logs in again.
+				a.client = nil
+			}
+			errs[i] = a.wrap(err)
+		})
+	}
+	wg.Wait()
+	return results, errs
+}
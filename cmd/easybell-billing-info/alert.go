@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/lmr-hh/easybell-billing-info/alert"
+)
+
+var (
+	thresholds alert.Thresholds
+	stateFile  string
+)
+
+func init() {
+	addForecastFlags(alertCommand)
+	addAlertFlags(alertCommand)
+	addOutputFlag(alertCommand)
+	addDryRunFlags(alertCommand)
+	rootCommand.AddCommand(alertCommand)
+}
+
+// addAlertFlags adds the flags that configure the thresholds of alerts to cmd.
+func addAlertFlags(cmd *cobra.Command) {
+	cmd.Flags().IntSliceVar(&thresholds.Quotas, "quota-thresholds", []int{80, 90, 100}, "Percentages of the national and mobile quotas that trigger an alert.")
+	cmd.Flags().Float64Var(&thresholds.Cost, "cost-threshold", 0, "The projected additional cost in Euro above which an alert is triggered. 0 disables the cost alert.")
+	cmd.Flags().StringVar(&stateFile, "state-file", defaultStateFile(), "The file in which fired alerts are recorded.")
+	checkFlag(cmd, "quota-thresholds", func() error { return alert.Thresholds{Quotas: thresholds.Quotas}.Validate() })
+	checkFlag(cmd, "cost-threshold", func() error { return alert.Thresholds{Cost: thresholds.Cost}.Validate() })
+}
+
+// defaultStateFile returns the path of the alert state file in the state directory of the user.
+func defaultStateFile() string {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "easybell-alerts.json"
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "easybell-billing-info", "alerts.json")
+}
+
+var alertCommand = &cobra.Command{
+	Use:   "alert",
+	Short: "Send the current billing period's report when a usage or cost threshold is crossed for the first time.",
+	Args:  cobra.NoArgs,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := loadModel(); err != nil {
+			return err
+		}
+		return loadDryRun()
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runAlert(cmd.Context())
+	},
+}
+
+// union returns the alerts of a followed by the alerts of b that are not contained in a.
+func union(a, b []alert.Alert) []alert.Alert {
+	for _, al := range b {
+		if !slices.Contains(a, al) {
+			a = append(a, al)
+		}
+	}
+	return a
+}
+
+// runAlert sends the reports of the current billing period of all accounts that cross a threshold for the first time.
+func runAlert(ctx context.Context) error {
+	state, err := alert.Load(stateFile)
+	if err != nil {
+		return fmt.Errorf("invalid alert state: %w", err)
+	}
+	out, err := newOutput()
+	if err != nil {
+		return err
+	}
+	reports, errs := currentReports(time.Now().In(location), false)
+	for i, a := range accounts {
+		if errs[i] != nil {
+			continue
+		}
+		r := reports[i]
+		evaluated := thresholds.Evaluate(r)
+		// Accounts without notification targets record the alerts that have been written to the output.
+		targets := a.Targets
+		if len(targets) == 0 {
+			targets = []string{""}
+		}
+		pending := make([][]alert.Alert, len(targets))
+		for j, target := range targets {
+			pending[j] = state.New(a.Name, target, r.Start, evaluated)
+			r.Alerts = union(r.Alerts, pending[j])
+		}
+		if len(r.Alerts) == 0 {
+			continue
+		}
+		if err := out.Write(r); err != nil {
+			errs = append(errs, err)
+		}
+		// Every target only receives the alerts that it has not received yet,
+		// and alerts are only recorded after they have been delivered so that failed notifications are retried.
+		// Dry runs do not record alerts so that they are sent by the next regular run.
+		var targetErrs []error
+		for j, target := range targets {
+			if len(pending[j]) == 0 {
+				continue
+			}
+			var err error
+			if j < len(a.Notifier) {
+				targetReport := *r
+				targetReport.Alerts = pending[j]
+				err = a.Notifier[j].Notify(ctx, &targetReport)
+			}
+			if err == nil && !dryRun {
+				state.Record(a.Name, target, r.Start, pending[j])
+			}
+			targetErrs = append(targetErrs, err)
+		}
+		errs[i] = a.wrap(errors.Join(targetErrs...))
+	}
+	if !dryRun {
+		if err = state.Save(stateFile); err != nil {
+			errs = append(errs, fmt.Errorf("could not save alert state: %w", err))
+		}
+	}
+	return errors.Join(append(errs, out.Close())...)
+}
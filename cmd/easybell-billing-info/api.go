@@ -0,0 +1,377 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lmr-hh/easybell-billing-info/dashboard"
+	"github.com/lmr-hh/easybell-billing-info/easybell"
+	"github.com/lmr-hh/easybell-billing-info/report"
+)
+
+// apiTokens contains the bearer tokens that grant access to the REST API.
+// If no tokens are configured, the API is disabled.
+var apiTokens []string
+
+// minAPITokenLength is the minimum length of API tokens.
+const minAPITokenLength = 16
+
+// validateAPITokens checks that all API tokens are long enough and registers them as secrets.
+func validateAPITokens() error {
+	for _, t := range apiTokens {
+		if len(t) < minAPITokenLength {
+			return fmt.Errorf("API tokens must have at least %d characters", minAPITokenLength)
+		}
+		registerSecret(t)
+	}
+	return nil
+}
+
+// registerAPI adds the handlers of the REST API and the dashboard to mux if API tokens are configured.
+// The static assets of the dashboard are public, its data is protected like the rest of the API.
+func registerAPI(mux *http.ServeMux) {
+	if len(apiTokens) == 0 {
+		return
+	}
+	mux.HandleFunc("GET /api/v1/usage", requireToken(handleUsage))
+	mux.HandleFunc("GET /api/v1/calls", requireToken(handleCalls))
+	mux.HandleFunc("GET /api/v1/forecast", requireToken(handleForecast))
+	mux.HandleFunc("GET /api/v1/dashboard", requireToken(handleDashboard))
+	mux.Handle("GET /", http.FileServerFS(dashboard.Assets))
+}
+
+// requireToken returns a handler that calls h only if the request contains a valid bearer token.
+func requireToken(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		valid := false
+		for _, t := range apiTokens {
+			// All tokens are compared so that the response time does not reveal which token matched.
+			if subtle.ConstantTimeCompare([]byte(token), []byte(t)) == 1 {
+				valid = true
+			}
+		}
+		if !ok || !valid {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="easybell-billing-info"`)
+			writeError(w, http.StatusUnauthorized, errors.New("missing or invalid bearer token"))
+			return
+		}
+		h(w, r)
+	}
+}
+
+// A callFilter selects calls of the call log.
+// The filters correspond to the fields of [easybell.CallLogReader].
+// Calls are selected if their time is in the interval between from (inclusive) and to (exclusive).
+type callFilter struct {
+	account   string
+	from, to  time.Time
+	number    string
+	partner   string
+	direction string
+	callType  string
+	kind      string
+}
+
+var (
+	// errUnknownAccount indicates that a request references an account that is not configured.
+	errUnknownAccount = errors.New("unknown account")
+	// errFetchUsage indicates that the cached usage of an account is not available.
+	errFetchUsage = errors.New("could not fetch the usage")
+)
+
+// parseCallFilter parses the query parameters of the API.
+// By default, the filter selects the successful outbound calls of the current billing period of all accounts.
+func parseCallFilter(q url.Values, now time.Time) (callFilter, error) {
+	f := callFilter{
+		account:   q.Get("account"),
+		number:    q.Get("number"),
+		partner:   q.Get("partner"),
+		direction: q.Get("direction"),
+		callType:  q.Get("type"),
+		kind:      q.Get("kind"),
+	}
+	if f.direction == "" {
+		f.direction = easybell.CallDirectionSuccessfulOutbound
+	}
+	if f.account != "" && !slices.ContainsFunc(accounts, func(a *account) bool { return a.Name == f.account }) {
+		return f, errUnknownAccount
+	}
+	f.from, f.to = cycle.Period(now)
+	var err error
+	if v := q.Get("from"); v != "" {
+		if f.from, err = parseAPITime(v, false); err != nil {
+			return f, fmt.Errorf("invalid from: %w", err)
+		}
+	}
+	if v := q.Get("to"); v != "" {
+		if f.to, err = parseAPITime(v, true); err != nil {
+			return f, fmt.Errorf("invalid to: %w", err)
+		}
+	}
+	if !f.from.Before(f.to) {
+		return f, errors.New("from must be before to")
+	}
+	return f, nil
+}
+
+// parseAPITime parses a date (YYYY-MM-DD) in the time zone of the billing periods or an RFC 3339 time.
+// If end is true, dates refer to the end of the day so that date ranges include their last day.
+func parseAPITime(v string, end bool) (time.Time, error) {
+	if t, err := time.ParseInLocation(time.DateOnly, v, location); err == nil {
+		if end {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return t, errors.New("must be a date (YYYY-MM-DD) or an RFC 3339 time")
+	}
+	return t, nil
+}
+
+// selects reports whether f selects the calls of a.
+func (f *callFilter) selects(a *account) bool {
+	return f.account == "" || f.account == a.Name
+}
+
+// matches reports whether f selects the call e.
+// The direction is not checked because the direction of a call is only known to easyBell.
+func (f *callFilter) matches(e *easybell.CallLogEntry) bool {
+	return !e.Time.Before(f.from) && e.Time.Before(f.to) &&
+		strings.Contains(e.Number, f.number) &&
+		strings.Contains(strings.ToLower(e.Partner), strings.ToLower(f.partner)) &&
+		(f.callType == "" || f.callType == easybell.CallTypeAny || f.callType == e.CallType) &&
+		(f.kind == "" || f.kind == easybell.CallKindAny || f.kind == e.Kind)
+}
+
+// readCalls returns the calls selected by f in the order of accounts.
+// The cache only contains the successful outbound calls of the current billing period.
+// Other calls are read from easyBell.
+func readCalls(f callFilter, now time.Time) ([][]easybell.CallLogEntry, error) {
+	calls := make([][]easybell.CallLogEntry, len(accounts))
+	start, end := cycle.Period(now)
+	if !f.from.Before(start) && !f.to.After(end) && f.direction == easybell.CallDirectionSuccessfulOutbound {
+		s := cache.get()
+		for i, a := range accounts {
+			if !f.selects(a) {
+				continue
+			}
+			r := s.Accounts[i].Report
+			if r == nil {
+				return nil, a.wrap(errFetchUsage)
+			}
+			for _, e := range r.Calls {
+				if f.matches(&e) {
+					calls[i] = append(calls[i], e)
+				}
+			}
+		}
+		return calls, nil
+	}
+
+	results, errs := forEachAccount(func(a *account, client *easybell.Client) ([]easybell.CallLogEntry, error) {
+		if !f.selects(a) {
+			return nil, nil
+		}
+		reader := easybell.NewCallLogReader(client, f.from, f.to)
+		reader.NumberFilter = f.number
+		reader.PartnerFilter = f.partner
+		reader.Direction = f.direction
+		reader.Type = f.callType
+		reader.Kind = f.kind
+		return reader.ReadAll()
+	})
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	for i, entries := range results {
+		for _, e := range entries {
+			// The end of the interval is exclusive, which easyBell does not guarantee.
+			if !e.Time.Before(f.from) && e.Time.Before(f.to) {
+				calls[i] = append(calls[i], e)
+			}
+		}
+	}
+	return calls, nil
+}
+
+// apiUsage is the response of the usage endpoint.
+type apiUsage struct {
+	From     time.Time         `json:"from"`
+	To       time.Time         `json:"to"`
+	Accounts []apiAccountUsage `json:"accounts"`
+}
+
+// apiAccountUsage is the usage of a single account.
+// Numbers is only set if the usage is grouped by number.
+type apiAccountUsage struct {
+	Account string               `json:"account,omitempty"`
+	Usage   report.DocumentUsage `json:"usage"`
+	Calls   int                  `json:"calls"`
+	Numbers []apiNumberUsage     `json:"numbers,omitempty"`
+}
+
+// apiNumberUsage is the usage of a single phone number of an account.
+type apiNumberUsage struct {
+	Number string               `json:"number"`
+	Usage  report.DocumentUsage `json:"usage"`
+	Calls  int                  `json:"calls"`
+}
+
+// handleUsage serves the usage of the successful outbound calls between from and to.
+// If by=number, the usage is also grouped by the phone number of the account.
+func handleUsage(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	by := q.Get("by")
+	if by != "" && by != "number" {
+		writeError(w, http.StatusBadRequest, errors.New("invalid by: must be number"))
+		return
+	}
+	f, ok := requestFilter(w, r)
+	if !ok {
+		return
+	}
+	if f.direction != easybell.CallDirectionSuccessfulOutbound {
+		// Calls in other directions are not billed.
+		writeError(w, http.StatusBadRequest, errors.New("invalid direction: the usage only includes successful outbound calls"))
+		return
+	}
+	calls, ok := requestCalls(w, f)
+	if !ok {
+		return
+	}
+	resp := apiUsage{From: f.from, To: f.to, Accounts: []apiAccountUsage{}}
+	for i, a := range accounts {
+		if !f.selects(a) {
+			continue
+		}
+		var usage easybell.Usage
+		perNumber := make(map[string]*easybell.Usage)
+		counts := make(map[string]int)
+		for _, e := range calls[i] {
+			usage.Add(&e)
+			if by == "number" {
+				if perNumber[e.Number] == nil {
+					perNumber[e.Number] = &easybell.Usage{}
+				}
+				perNumber[e.Number].Add(&e)
+				counts[e.Number]++
+			}
+		}
+		u := apiAccountUsage{Account: a.Name, Usage: report.NewDocumentUsage(usage), Calls: len(calls[i])}
+		for number, n := range perNumber {
+			u.Numbers = append(u.Numbers, apiNumberUsage{Number: number, Usage: report.NewDocumentUsage(*n), Calls: counts[number]})
+		}
+		slices.SortFunc(u.Numbers, func(a, b apiNumberUsage) int { return strings.Compare(a.Number, b.Number) })
+		resp.Accounts = append(resp.Accounts, u)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// apiCalls is the response of the calls endpoint.
+type apiCalls struct {
+	From  time.Time             `json:"from"`
+	To    time.Time             `json:"to"`
+	Calls []report.DocumentCall `json:"calls"`
+}
+
+// handleCalls serves the calls that match the filters of the request ordered by time.
+func handleCalls(w http.ResponseWriter, r *http.Request) {
+	f, ok := requestFilter(w, r)
+	if !ok {
+		return
+	}
+	calls, ok := requestCalls(w, f)
+	if !ok {
+		return
+	}
+	resp := apiCalls{From: f.from, To: f.to, Calls: []report.DocumentCall{}}
+	for i, a := range accounts {
+		for _, e := range calls[i] {
+			resp.Calls = append(resp.Calls, report.NewDocumentCall(a.Name, &e))
+		}
+	}
+	slices.SortStableFunc(resp.Calls, func(a, b report.DocumentCall) int { return a.Time.Compare(b.Time) })
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// apiForecast is the response of the forecast endpoint.
+type apiForecast struct {
+	// Time is the time at which the usage was fetched from easyBell.
+	Time    time.Time         `json:"time"`
+	Reports []report.Document `json:"reports"`
+}
+
+// handleForecast serves the reports of the current billing period including the forecast from the cache.
+func handleForecast(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("account")
+	if name != "" && !slices.ContainsFunc(accounts, func(a *account) bool { return a.Name == name }) {
+		writeError(w, http.StatusNotFound, errUnknownAccount)
+		return
+	}
+	s := cache.get()
+	resp := apiForecast{Time: s.Time, Reports: []report.Document{}}
+	for i, a := range accounts {
+		if name != "" && a.Name != name {
+			continue
+		}
+		if s.Accounts[i].Report == nil {
+			writeError(w, http.StatusBadGateway, a.wrap(errFetchUsage))
+			return
+		}
+		resp.Reports = append(resp.Reports, s.Accounts[i].Report.Document())
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// requestFilter parses the call filter of r.
+// If the filter is invalid, an error response is written and ok is false.
+func requestFilter(w http.ResponseWriter, r *http.Request) (f callFilter, ok bool) {
+	f, err := parseCallFilter(r.URL.Query(), time.Now().In(location))
+	switch {
+	case errors.Is(err, errUnknownAccount):
+		writeError(w, http.StatusNotFound, err)
+	case err != nil:
+		writeError(w, http.StatusBadRequest, err)
+	}
+	return f, err == nil
+}
+
+// requestCalls reads the calls selected by f.
+// If the calls cannot be read, an error response is written and ok is false.
+func requestCalls(w http.ResponseWriter, f callFilter) (calls [][]easybell.CallLogEntry, ok bool) {
+	calls, err := readCalls(f, time.Now().In(location))
+	switch {
+	case errors.Is(err, easybell.ErrBadFilter):
+		writeError(w, http.StatusBadRequest, err)
+	case err != nil:
+		log.Printf("API: %v", redact(err))
+		writeError(w, http.StatusBadGateway, err)
+	}
+	return calls, err == nil
+}
+
+// writeJSON writes v as JSON response with the status code.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes err as JSON response with the status code.
+// Secrets are removed from the error message.
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, struct {
+		Error string `json:"error"`
+	}{redact(err).Error()})
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/lmr-hh/easybell-billing-info/easybell"
+	"github.com/lmr-hh/easybell-billing-info/forecast"
+	"github.com/lmr-hh/easybell-billing-info/holiday"
+	"github.com/lmr-hh/easybell-billing-info/report"
+)
+
+var (
+	backtestPeriods int
+	backtestModels  []string
+	backtestWindows []time.Duration
+
+	// backtestModelList contains the forecast models in the order of backtestModels.
+	backtestModelList []forecast.Model
+)
+
+func init() {
+	backtestCommand.Flags().IntVar(&backtestPeriods, "periods", 6, "The number of past billing periods to replay.")
+	backtestCommand.Flags().StringSliceVar(&backtestModels, "models", forecast.Models, "The forecast models to evaluate.")
+	backtestCommand.Flags().DurationSliceVar(&backtestWindows, "estimate", []time.Duration{7 * 24 * time.Hour, 14 * 24 * time.Hour, 35 * 24 * time.Hour}, "The estimation periods to evaluate.")
+	backtestCommand.Flags().StringVar(&holidayState, "holidays", "", "The federal state whose public holidays are considered by the weekday model (e.g. HH).")
+	checkFlag(backtestCommand, "estimate", func() error {
+		for _, window := range backtestWindows {
+			if err := checkEstimationPeriod(window); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	checkFlag(backtestCommand, "holidays", checkHolidays)
+	addOutputFlag(backtestCommand)
+	rootCommand.AddCommand(backtestCommand)
+}
+
+// backtestCommand evaluates the accuracy of the forecast models on past billing periods.
+var backtestCommand = &cobra.Command{
+	Use:   "backtest",
+	Short: "Evaluate the accuracy of the forecast models on past billing periods.",
+	Long: "Replay past billing periods and run every forecast model as if it was each day of the\n" +
+		"period. The forecasts are compared with the actual usage at the end of the period.",
+	Args: cobra.NoArgs,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if backtestPeriods < 1 {
+			return errors.New("at least 1 period is required")
+		}
+		if len(backtestWindows) == 0 {
+			return errors.New("at least 1 estimation period is required")
+		}
+		holidays, err := holiday.NewCalendar(holidayState)
+		if err != nil {
+			return err
+		}
+		backtestModelList = make([]forecast.Model, len(backtestModels))
+		for i, name := range backtestModels {
+			if backtestModelList[i], err = forecast.New(name, holidays); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out, err := newOutput()
+		if err != nil {
+			return err
+		}
+		periods := make([][2]time.Time, backtestPeriods)
+		t := time.Now().In(location)
+		for i := range periods {
+			periods[i][0], periods[i][1] = cycle.Previous(t)
+			t = periods[i][0]
+		}
+		from := periods[len(periods)-1][0].Add(-slices.Max(backtestWindows))
+		to := periods[0][1]
+
+		results, errs := forEachAccount(func(a *account, client *easybell.Client) ([][]backtestErrors, error) {
+			reader := easybell.NewCallLogReader(client, from, to)
+			reader.Direction = easybell.CallDirectionSuccessfulOutbound
+			calls, err := reader.ReadAll()
+			if err != nil {
+				return nil, err
+			}
+			return runBacktest(calls, periods), nil
+		})
+		for i, a := range accounts {
+			if errs[i] != nil {
+				continue
+			}
+			if err := out.WriteBacktest(newBacktest(a, periods[len(periods)-1][0], to, results[i])); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(append(errs, out.Close())...)
+	},
+}
+
+// runBacktest replays the forecasts of every model and estimation period on the calls in periods.
+// The results are indexed by model and estimation period.
+func runBacktest(calls []easybell.CallLogEntry, periods [][2]time.Time) [][]backtestErrors {
+	results := make([][]backtestErrors, len(backtestModelList))
+	for i, model := range backtestModelList {
+		results[i] = make([]backtestErrors, len(backtestWindows))
+		for j, window := range backtestWindows {
+			for _, period := range periods {
+				start, end := period[0], period[1]
+				actual := forecast.Sum(forecast.Days(calls, start, end))
+				for now := start.AddDate(0, 0, 1); now.Before(end); now = now.AddDate(0, 0, 1) {
+					estimate := model.Forecast(forecast.Input{
+						Start:   start,
+						End:     end,
+						Now:     now,
+						Current: forecast.Sum(forecast.Days(calls, start, now)),
+						Window:  window,
+						History: forecast.Days(calls, now.Add(-window), now),
+					})
+					results[i][j].add(estimate, actual)
+				}
+			}
+		}
+	}
+	return results
+}
+
+// backtestErrors accumulates the errors of forecasts compared to the actual usage.
+type backtestErrors struct {
+	count int
+	// absolute and signed errors in minutes, indexed by national and mobile.
+	absolute [2]float64
+	signed   [2]float64
+	// relative contains the sum of relative errors, relCount the number of forecasts with a non-zero actual usage.
+	relative [2]float64
+	relCount [2]int
+}
+
+// add records the error of a single forecast.
+func (e *backtestErrors) add(estimate, actual easybell.Usage) {
+	e.count++
+	for k, values := range [2][2]time.Duration{{estimate.National, actual.National}, {estimate.Mobile, actual.Mobile}} {
+		diff := values[0].Minutes() - values[1].Minutes()
+		e.absolute[k] += math.Abs(diff)
+		e.signed[k] += diff
+		if values[1] > 0 {
+			e.relative[k] += math.Abs(diff) / values[1].Minutes()
+			e.relCount[k]++
+		}
+	}
+}
+
+// mae returns the mean absolute error in minutes.
+func (e *backtestErrors) mae(k int) float64 {
+	return e.absolute[k] / float64(max(e.count, 1))
+}
+
+// bias returns the mean signed error in minutes.
+// A positive bias indicates that the model overestimates the usage.
+func (e *backtestErrors) bias(k int) float64 {
+	return e.signed[k] / float64(max(e.count, 1))
+}
+
+// mape returns the mean absolute percentage error.
+func (e *backtestErrors) mape(k int) float64 {
+	return e.relative[k] / float64(max(e.relCount[k], 1)) * 100
+}
+
+// newBacktest returns the backtest of account a from the error metrics of every model and estimation period.
+func newBacktest(a *account, from, to time.Time, results [][]backtestErrors) *report.Backtest {
+	b := &report.Backtest{Account: a.Name, Start: from, End: to}
+	for i, name := range backtestModels {
+		for j, window := range backtestWindows {
+			e := &results[i][j]
+			b.Results = append(b.Results, report.BacktestResult{
+				Model:     name,
+				Window:    window,
+				Forecasts: e.count,
+				National:  report.ForecastError{MAE: minutes(e.mae(0)), MAPE: e.mape(0), Bias: minutes(e.bias(0))},
+				Mobile:    report.ForecastError{MAE: minutes(e.mae(1)), MAPE: e.mape(1), Bias: minutes(e.bias(1))},
+			})
+		}
+	}
+	return b
+}
+
+// minutes returns the duration of m minutes.
+func minutes(m float64) time.Duration {
+	return time.Duration(math.Round(m * float64(time.Minute)))
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/lmr-hh/easybell-billing-info/metrics"
+)
+
+// usageCache caches the usage of the current billing period of all accounts
+// so that HTTP requests do not access easyBell every time.
+type usageCache struct {
+	// ttl is the time after which the usage is fetched again.
+	ttl      time.Duration
+	mu       sync.Mutex
+	snapshot *metrics.Snapshot
+}
+
+// get returns the cached snapshot or fetches a new one if it is older than the ttl of c.
+// Failed fetches are cached as well so that an unavailable portal is not hit by every request.
+func (c *usageCache) get() *metrics.Snapshot {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.snapshot == nil || time.Since(c.snapshot.Time) >= c.ttl {
+		c.snapshot = fetchSnapshot()
+	}
+	return c.snapshot
+}
+
+// fetchSnapshot fetches the usage of the current billing period of all accounts including their calls.
+// Errors are logged and the reports of the respective accounts are omitted.
+func fetchSnapshot() *metrics.Snapshot {
+	start := time.Now()
+	reports, errs := currentReports(start.In(location), true)
+	s := &metrics.Snapshot{Time: start, Duration: time.Since(start)}
+	for i, a := range accounts {
+		if errs[i] != nil {
+			log.Printf("could not fetch usage: %v", redact(errs[i]))
+			reports[i] = nil
+		}
+		s.Accounts = append(s.Accounts, metrics.Account{Name: a.Name, Report: reports[i]})
+	}
+	return s
+}
@@ -0,0 +1,799 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	goteamsnotify "github.com/atc0005/go-teams-notify/v2"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+
+	"github.com/lmr-hh/easybell-billing-info/alert"
+	"github.com/lmr-hh/easybell-billing-info/cron"
+	"github.com/lmr-hh/easybell-billing-info/forecast"
+	"github.com/lmr-hh/easybell-billing-info/holiday"
+	"github.com/lmr-hh/easybell-billing-info/i18n"
+	"github.com/lmr-hh/easybell-billing-info/notify"
+	"github.com/lmr-hh/easybell-billing-info/report"
+)
+
+func init() {
+	configCommand.AddCommand(configValidateCommand)
+	rootCommand.AddCommand(configCommand)
+}
+
+// configCommand groups commands that work with the configuration.
+var configCommand = &cobra.Command{
+	Use:   "config",
+	Short: "Work with the configuration.",
+	Args:  cobra.NoArgs,
+	// The config commands must not log in to easyBell.
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return nil
+	},
+}
+
+// configValidateCommand validates the configuration without sending any reports.
+var configValidateCommand = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate the configuration file, environment and flags.",
+	Long: "Validate the configuration file, environment variables and flags and report all\n" +
+		"errors at once. The easyBell credentials are not verified.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := loadConfig(cmd)
+		if envErrs := validateEnvironment(cmd); len(envErrs) > 0 {
+			err = errors.Join(append([]error{err}, envErrs...)...)
+		}
+		if err == nil {
+			fmt.Println("The configuration is valid.")
+			return nil
+		}
+		errs := flattenErrors(err)
+		fmt.Printf("The configuration contains %d error(s):\n", len(errs))
+		for _, e := range errs {
+			fmt.Printf("  - %s\n", redact(e))
+		}
+		return errors.New("invalid configuration")
+	},
+}
+
+// flattenErrors returns the individual errors of errors joined by [errors.Join].
+func flattenErrors(err error) []error {
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []error{err}
+	}
+	var errs []error
+	for _, e := range joined.Unwrap() {
+		errs = append(errs, flattenErrors(e)...)
+	}
+	return errs
+}
+
+// config is the schema of the configuration file.
+// Settings in the configuration file have the lowest precedence.
+// They are overridden by environment variables which in turn are overridden by command line flags.
+type config struct {
+	Credentials   credentialsConfig   `yaml:"credentials"`
+	Tariff        tariffConfig        `yaml:"tariff"`
+	Billing       billingConfig       `yaml:"billing"`
+	Forecast      forecastConfig      `yaml:"forecast"`
+	Alerts        alertsConfig        `yaml:"alerts"`
+	Server        serverConfig        `yaml:"server"`
+	Metrics       metricsConfig       `yaml:"metrics"`
+	Notifications notificationsConfig `yaml:"notifications"`
+	Schedules     []scheduleConfig    `yaml:"schedules"`
+	Locale        string              `yaml:"locale"`
+	Output        string              `yaml:"output"`
+	Accounts      []accountConfig     `yaml:"accounts"`
+	Summary       *bool               `yaml:"summary"`
+}
+
+// accountConfig describes one of several easyBell accounts.
+// Tariff and notification settings that are not set for an account are inherited from the global settings.
+type accountConfig struct {
+	Name          string              `yaml:"name"`
+	Credentials   credentialsConfig   `yaml:"credentials"`
+	Tariff        tariffConfig        `yaml:"tariff"`
+	Notifications notificationsConfig `yaml:"notifications"`
+}
+
+// credentialsConfig contains the easyBell login.
+// Username and Password may reference environment variables as ${NAME}.
+// Only one of Password, PasswordFile and PasswordCommand may be set.
+type credentialsConfig struct {
+	Username        string   `yaml:"username"`
+	Password        string   `yaml:"password"`
+	PasswordFile    string   `yaml:"password_file"`
+	PasswordCommand []string `yaml:"password_command"`
+}
+
+// tariffConfig contains the included quotas and the prices of additional minutes.
+type tariffConfig struct {
+	NationalMinutes *time.Duration `yaml:"national_minutes"`
+	MobileMinutes   *time.Duration `yaml:"mobile_minutes"`
+	NationalPrice   *float64       `yaml:"national_price"`
+	MobilePrice     *float64       `yaml:"mobile_price"`
+}
+
+// billingConfig determines the billing periods.
+type billingConfig struct {
+	Day      *int     `yaml:"day"`
+	Dates    []string `yaml:"dates"`
+	Timezone string   `yaml:"timezone"`
+}
+
+// forecastConfig contains the settings of the current-month forecast.
+type forecastConfig struct {
+	Model      string         `yaml:"model"`
+	Estimate   *time.Duration `yaml:"estimate"`
+	Confidence *float64       `yaml:"confidence"`
+	Holidays   string         `yaml:"holidays"`
+}
+
+// alertsConfig contains the thresholds of the alert command.
+type alertsConfig struct {
+	Quotas    []int    `yaml:"quotas"`
+	Cost      *float64 `yaml:"cost"`
+	StateFile string   `yaml:"state_file"`
+}
+
+// serverConfig contains the HTTP settings of the serve command.
+// The APITokens may reference environment variables as ${NAME}.
+type serverConfig struct {
+	Listen    string         `yaml:"listen"`
+	CacheTTL  *time.Duration `yaml:"cache_ttl"`
+	APITokens []string       `yaml:"api_tokens"`
+}
+
+// metricsConfig contains the destinations of the metrics command.
+// The InfluxURL and InfluxToken may reference environment variables as ${NAME}.
+// Only one of InfluxToken and InfluxTokenFile may be set.
+type metricsConfig struct {
+	Textfile        string `yaml:"textfile"`
+	InfluxURL       string `yaml:"influx_url"`
+	InfluxToken     string `yaml:"influx_token"`
+	InfluxTokenFile string `yaml:"influx_token_file"`
+	Format          string `yaml:"format"`
+}
+
+// notificationsConfig contains the notification targets.
+type notificationsConfig struct {
+	Teams      teamsConfig       `yaml:"teams"`
+	Slack      webhookConfig     `yaml:"slack"`
+	Mattermost webhookConfig     `yaml:"mattermost"`
+	Discord    webhookConfig     `yaml:"discord"`
+	Matrix     matrixConfig      `yaml:"matrix"`
+	Ntfy       ntfyConfig        `yaml:"ntfy"`
+	Gotify     gotifyConfig      `yaml:"gotify"`
+	Email      emailConfig       `yaml:"email"`
+	Webhook    jsonWebhookConfig `yaml:"webhook"`
+}
+
+// webhookConfig configures a notification target that is identified by a webhook URL.
+// The WebhookURL may reference environment variables as ${NAME}.
+// Only one of WebhookURL and WebhookURLFile may be set.
+type webhookConfig struct {
+	Enabled        *bool  `yaml:"enabled"`
+	WebhookURL     string `yaml:"webhook_url"`
+	WebhookURLFile string `yaml:"webhook_url_file"`
+}
+
+// teamsConfig configures the Teams webhook.
+// The Type selects between Office 365 connectors and Power Automate workflows
+// and is detected from the webhook URL by default.
+// Templates is a directory with card templates that override the embedded ones.
+type teamsConfig struct {
+	webhookConfig `yaml:",inline"`
+	Type          string `yaml:"type"`
+	Templates     string `yaml:"templates"`
+}
+
+// matrixConfig configures sending reports to a Matrix room.
+// The AccessToken may reference environment variables as ${NAME}.
+// Only one of AccessToken and AccessTokenFile may be set.
+type matrixConfig struct {
+	Enabled         *bool  `yaml:"enabled"`
+	Homeserver      string `yaml:"homeserver"`
+	AccessToken     string `yaml:"access_token"`
+	AccessTokenFile string `yaml:"access_token_file"`
+	RoomID          string `yaml:"room_id"`
+}
+
+// ntfyConfig configures sending alerts to an ntfy topic.
+// The Token may reference environment variables as ${NAME}.
+// Only one of Token and TokenFile may be set.
+type ntfyConfig struct {
+	Enabled   *bool          `yaml:"enabled"`
+	Server    string         `yaml:"server"`
+	Topic     string         `yaml:"topic"`
+	Token     string         `yaml:"token"`
+	TokenFile string         `yaml:"token_file"`
+	ClickURL  string         `yaml:"click_url"`
+	MinStatus *report.Status `yaml:"min_status"`
+}
+
+// gotifyConfig configures sending alerts to a Gotify server.
+// The Token may reference environment variables as ${NAME}.
+// Only one of Token and TokenFile may be set.
+type gotifyConfig struct {
+	Enabled   *bool          `yaml:"enabled"`
+	URL       string         `yaml:"url"`
+	Token     string         `yaml:"token"`
+	TokenFile string         `yaml:"token_file"`
+	ClickURL  string         `yaml:"click_url"`
+	MinStatus *report.Status `yaml:"min_status"`
+}
+
+// emailConfig configures sending reports via SMTP.
+// The Username and Password may reference environment variables as ${NAME}.
+// Only one of Password and PasswordFile may be set.
+type emailConfig struct {
+	Enabled      *bool    `yaml:"enabled"`
+	Host         string   `yaml:"host"`
+	Port         int      `yaml:"port"`
+	Security     string   `yaml:"security"`
+	Username     string   `yaml:"username"`
+	Password     string   `yaml:"password"`
+	PasswordFile string   `yaml:"password_file"`
+	From         string   `yaml:"from"`
+	To           []string `yaml:"to"`
+	AttachCalls  *bool    `yaml:"attach_calls"`
+}
+
+// jsonWebhookConfig configures sending report documents as JSON to an HTTP endpoint.
+// The URL and Secret may reference environment variables as ${NAME}.
+// Only one of URL and URLFile, and only one of Secret and SecretFile may be set.
+type jsonWebhookConfig struct {
+	Enabled    *bool          `yaml:"enabled"`
+	URL        string         `yaml:"url"`
+	URLFile    string         `yaml:"url_file"`
+	Secret     string         `yaml:"secret"`
+	SecretFile string         `yaml:"secret_file"`
+	Retries    *int           `yaml:"retries"`
+	RetryDelay *time.Duration `yaml:"retry_delay"`
+}
+
+// scheduleConfig describes when a report command should run.
+// If Timezone is empty, the cron expression is interpreted in the time zone of the billing periods.
+type scheduleConfig struct {
+	Command  string `yaml:"command"`
+	Cron     string `yaml:"cron"`
+	Timezone string `yaml:"timezone"`
+}
+
+// scheduleCommands contains the commands that can be scheduled.
+var scheduleCommands = []string{"last-month", "current-month", "alert", "metrics"}
+
+// flagEnvironment lists the flags that can also be set via environment variables.
+// Secret values can also be read from the file referenced by the variable with a _FILE suffix.
+var flagEnvironment = []struct {
+	flag   string
+	env    string
+	secret bool
+}{
+	{"national-minutes", "EASYBELL_NATIONAL_MINUTES", false},
+	{"mobile-minutes", "EASYBELL_MOBILE_MINUTES", false},
+	{"national-price", "EASYBELL_NATIONAL_PRICE", false},
+	{"mobile-price", "EASYBELL_MOBILE_PRICE", false},
+	{"billing-day", "EASYBELL_BILLING_DAY", false},
+	{"billing-dates", "EASYBELL_BILLING_DATES", false},
+	{"timezone", "EASYBELL_TIMEZONE", false},
+	{"teams-webhook", "EASYBELL_TEAMS_ENABLED", false},
+	{"webhook-url", "EASYBELL_TEAMS_WEBHOOK", true},
+	{"webhook-type", "EASYBELL_TEAMS_WEBHOOK_TYPE", false},
+	{"card-templates", "EASYBELL_CARD_TEMPLATES", false},
+	{"model", "EASYBELL_FORECAST_MODEL", false},
+	{"estimate", "EASYBELL_ESTIMATE", false},
+	{"confidence", "EASYBELL_CONFIDENCE", false},
+	{"holidays", "EASYBELL_HOLIDAYS", false},
+	{"quota-thresholds", "EASYBELL_ALERT_QUOTAS", false},
+	{"cost-threshold", "EASYBELL_ALERT_COST", false},
+	{"state-file", "EASYBELL_ALERT_STATE_FILE", false},
+	{"listen", "EASYBELL_LISTEN", false},
+	{"cache-ttl", "EASYBELL_CACHE_TTL", false},
+	{"api-tokens", "EASYBELL_API_TOKENS", true},
+	{"textfile", "EASYBELL_METRICS_TEXTFILE", false},
+	{"influx-url", "EASYBELL_INFLUX_URL", true},
+	{"influx-token", "EASYBELL_INFLUX_TOKEN", true},
+	{"format", "EASYBELL_METRICS_FORMAT", false},
+	{"summary", "EASYBELL_SUMMARY", false},
+	{"locale", "EASYBELL_LOCALE", false},
+	{"output", "EASYBELL_OUTPUT", false},
+}
+
+// readConfig reads and parses the configuration file at path.
+// All syntax errors, type errors and unknown fields are reported at once.
+func readConfig(path string) (*config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	c := &config{}
+	decoder := yaml.NewDecoder(f)
+	decoder.KnownFields(true)
+	if err = decoder.Decode(c); err != nil {
+		var typeErr *yaml.TypeError
+		if errors.As(err, &typeErr) {
+			errs := make([]error, len(typeErr.Errors))
+			for i, msg := range typeErr.Errors {
+				errs[i] = fmt.Errorf("%s: %s", path, msg)
+			}
+			return c, errors.Join(errs...)
+		}
+		return c, fmt.Errorf("%s: %w", path, err)
+	}
+	return c, nil
+}
+
+// resolveSecrets expands environment variables in secrets and reads the secrets that are referenced by files or commands in c.
+func (c *config) resolveSecrets() (errs []error) {
+	errs = append(errs, c.Credentials.resolve("credentials")...)
+	errs = append(errs, c.Notifications.resolve("notifications")...)
+	errs = append(errs, c.Metrics.resolve("metrics")...)
+	for i, t := range c.Server.APITokens {
+		c.Server.APITokens[i] = registerSecret(os.ExpandEnv(t))
+	}
+	for i := range c.Accounts {
+		a := &c.Accounts[i]
+		errs = append(errs, a.Credentials.resolve(fmt.Sprintf("accounts[%d].credentials", i))...)
+		errs = append(errs, a.Notifications.resolve(fmt.Sprintf("accounts[%d].notifications", i))...)
+	}
+	return errs
+}
+
+// resolve reads the secrets of all notification targets in c.
+// The prefix is used in error messages.
+func (c *notificationsConfig) resolve(prefix string) (errs []error) {
+	errs = append(errs, c.Teams.resolve(prefix+".teams")...)
+	errs = append(errs, c.Slack.resolve(prefix+".slack")...)
+	errs = append(errs, c.Mattermost.resolve(prefix+".mattermost")...)
+	errs = append(errs, c.Discord.resolve(prefix+".discord")...)
+	errs = append(errs, c.Matrix.resolve(prefix+".matrix")...)
+	errs = append(errs, resolveToken(&c.Ntfy.Token, c.Ntfy.TokenFile, prefix+".ntfy")...)
+	errs = append(errs, resolveToken(&c.Gotify.Token, c.Gotify.TokenFile, prefix+".gotify")...)
+	errs = append(errs, c.Email.resolve(prefix+".email")...)
+	errs = append(errs, c.Webhook.resolve(prefix+".webhook")...)
+	return errs
+}
+
+// resolve expands environment variables in c and reads the password if it is referenced by a file or command.
+// The prefix is used in error messages.
+func (c *credentialsConfig) resolve(prefix string) (errs []error) {
+	c.Username = os.ExpandEnv(c.Username)
+	c.Password = registerSecret(os.ExpandEnv(c.Password))
+	switch {
+	case countSet(c.Password != "", c.PasswordFile != "", len(c.PasswordCommand) > 0) > 1:
+		errs = append(errs, fmt.Errorf("%s: only one of password, password_file and password_command may be set", prefix))
+	case c.PasswordFile != "":
+		var err error
+		if c.Password, err = readSecretFile(c.PasswordFile); err != nil {
+			errs = append(errs, fmt.Errorf("%s.password_file: %w", prefix, err))
+		}
+	case len(c.PasswordCommand) > 0:
+		var err error
+		if c.Password, err = runSecretCommand(c.PasswordCommand); err != nil {
+			errs = append(errs, fmt.Errorf("%s.password_command: %w", prefix, err))
+		}
+	}
+	return errs
+}
+
+// resolve expands environment variables in c and reads the webhook URL if it is referenced by a file.
+// The prefix is used in error messages.
+func (c *webhookConfig) resolve(prefix string) (errs []error) {
+	c.WebhookURL = registerSecret(os.ExpandEnv(c.WebhookURL))
+	switch {
+	case c.WebhookURL != "" && c.WebhookURLFile != "":
+		errs = append(errs, fmt.Errorf("%s: only one of webhook_url and webhook_url_file may be set", prefix))
+	case c.WebhookURLFile != "":
+		var err error
+		if c.WebhookURL, err = readSecretFile(c.WebhookURLFile); err != nil {
+			errs = append(errs, fmt.Errorf("%s.webhook_url_file: %w", prefix, err))
+		}
+	}
+	return errs
+}
+
+// resolve expands environment variables in c and reads the access token if it is referenced by a file.
+// The prefix is used in error messages.
+func (c *matrixConfig) resolve(prefix string) (errs []error) {
+	c.Homeserver = os.ExpandEnv(c.Homeserver)
+	c.AccessToken = registerSecret(os.ExpandEnv(c.AccessToken))
+	c.RoomID = os.ExpandEnv(c.RoomID)
+	switch {
+	case c.AccessToken != "" && c.AccessTokenFile != "":
+		errs = append(errs, fmt.Errorf("%s: only one of access_token and access_token_file may be set", prefix))
+	case c.AccessTokenFile != "":
+		var err error
+		if c.AccessToken, err = readSecretFile(c.AccessTokenFile); err != nil {
+			errs = append(errs, fmt.Errorf("%s.access_token_file: %w", prefix, err))
+		}
+	}
+	return errs
+}
+
+// resolveToken expands environment variables in token or reads it from tokenFile.
+// The prefix is used in error messages.
+func resolveToken(token *string, tokenFile, prefix string) (errs []error) {
+	*token = registerSecret(os.ExpandEnv(*token))
+	switch {
+	case *token != "" && tokenFile != "":
+		errs = append(errs, fmt.Errorf("%s: only one of token and token_file may be set", prefix))
+	case tokenFile != "":
+		var err error
+		if *token, err = readSecretFile(tokenFile); err != nil {
+			errs = append(errs, fmt.Errorf("%s.token_file: %w", prefix, err))
+		}
+	}
+	return errs
+}
+
+// resolve expands environment variables in c and reads the InfluxDB token if it is referenced by a file.
+// The prefix is used in error messages.
+func (c *metricsConfig) resolve(prefix string) (errs []error) {
+	c.InfluxURL = registerSecret(os.ExpandEnv(c.InfluxURL))
+	c.InfluxToken = registerSecret(os.ExpandEnv(c.InfluxToken))
+	switch {
+	case c.InfluxToken != "" && c.InfluxTokenFile != "":
+		errs = append(errs, fmt.Errorf("%s: only one of influx_token and influx_token_file may be set", prefix))
+	case c.InfluxTokenFile != "":
+		var err error
+		if c.InfluxToken, err = readSecretFile(c.InfluxTokenFile); err != nil {
+			errs = append(errs, fmt.Errorf("%s.influx_token_file: %w", prefix, err))
+		}
+	}
+	return errs
+}
+
+// resolve expands environment variables in c and reads the password if it is referenced by a file.
+// The prefix is used in error messages.
+func (c *emailConfig) resolve(prefix string) (errs []error) {
+	c.Username = os.ExpandEnv(c.Username)
+	c.Password = registerSecret(os.ExpandEnv(c.Password))
+	switch {
+	case c.Password != "" && c.PasswordFile != "":
+		errs = append(errs, fmt.Errorf("%s: only one of password and password_file may be set", prefix))
+	case c.PasswordFile != "":
+		var err error
+		if c.Password, err = readSecretFile(c.PasswordFile); err != nil {
+			errs = append(errs, fmt.Errorf("%s.password_file: %w", prefix, err))
+		}
+	}
+	return errs
+}
+
+// resolve expands environment variables in c and reads the URL and the secret if they are referenced by files.
+// The prefix is used in error messages.
+func (c *jsonWebhookConfig) resolve(prefix string) (errs []error) {
+	c.URL = registerSecret(os.ExpandEnv(c.URL))
+	c.Secret = registerSecret(os.ExpandEnv(c.Secret))
+	switch {
+	case c.URL != "" && c.URLFile != "":
+		errs = append(errs, fmt.Errorf("%s: only one of url and url_file may be set", prefix))
+	case c.URLFile != "":
+		var err error
+		if c.URL, err = readSecretFile(c.URLFile); err != nil {
+			errs = append(errs, fmt.Errorf("%s.url_file: %w", prefix, err))
+		}
+	}
+	switch {
+	case c.Secret != "" && c.SecretFile != "":
+		errs = append(errs, fmt.Errorf("%s: only one of secret and secret_file may be set", prefix))
+	case c.SecretFile != "":
+		var err error
+		if c.Secret, err = readSecretFile(c.SecretFile); err != nil {
+			errs = append(errs, fmt.Errorf("%s.secret_file: %w", prefix, err))
+		}
+	}
+	return errs
+}
+
+// validateTeamsWebhook checks that url is a valid Teams webhook URL of the specified kind.
+// If kind is [notify.TeamsAuto], the kind must be detectable from the URL.
+// The returned error does not contain the URL because it is a secret.
+func validateTeamsWebhook(url, kind string) error {
+	if !slices.Contains(notify.TeamsWebhookTypes, kind) {
+		return fmt.Errorf("invalid Teams webhook type: must be one of %s", strings.Join(notify.TeamsWebhookTypes, ", "))
+	}
+	if kind != notify.TeamsAuto {
+		return validateWebhookURL("Teams", url)
+	}
+	if url == "" {
+		return errors.New("no Teams webhook URL specified")
+	}
+	if _, err := notify.TeamsWebhookType(url); err != nil {
+		return errors.New("invalid Teams webhook URL: not a connector or workflow URL, set the webhook type explicitly")
+	}
+	return nil
+}
+
+// validateWebhookURL checks that rawURL is an absolute HTTP(S) URL.
+// The returned error does not contain the URL because it is a secret.
+func validateWebhookURL(service, rawURL string) error {
+	if rawURL == "" {
+		return fmt.Errorf("no %s webhook URL specified", service)
+	}
+	if !isHTTPURL(rawURL) {
+		return fmt.Errorf("invalid %s webhook URL", service)
+	}
+	return nil
+}
+
+// isHTTPURL indicates whether rawURL is an absolute HTTP(S) URL.
+func isHTTPURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	return err == nil && (u.Scheme == "https" || u.Scheme == "http") && u.Host != ""
+}
+
+// countSet returns the number of true values.
+func countSet(values ...bool) (n int) {
+	for _, v := range values {
+		if v {
+			n++
+		}
+	}
+	return n
+}
+
+// validate checks the values of c that cannot be checked by the YAML decoder.
+// Values that are applied to global flags are validated by loadConfig instead.
+func (c *config) validate() (errs []error) {
+	if c.Forecast.Model != "" && !slices.Contains(forecast.Models, c.Forecast.Model) {
+		errs = append(errs, fmt.Errorf("forecast.model: must be one of %s", strings.Join(forecast.Models, ", ")))
+	}
+	if c.Forecast.Estimate != nil && *c.Forecast.Estimate <= 24*time.Hour {
+		errs = append(errs, errors.New("forecast.estimate: must be at least 1 day"))
+	}
+	if c.Forecast.Confidence != nil && (*c.Forecast.Confidence <= 0 || *c.Forecast.Confidence >= 1) {
+		errs = append(errs, errors.New("forecast.confidence: must be between 0 and 1"))
+	}
+	if _, err := holiday.NewCalendar(c.Forecast.Holidays); err != nil {
+		errs = append(errs, fmt.Errorf("forecast.holidays: %w", err))
+	}
+	t := alert.Thresholds{Quotas: c.Alerts.Quotas}
+	if c.Alerts.Cost != nil {
+		t.Cost = *c.Alerts.Cost
+	}
+	if err := t.Validate(); err != nil {
+		for _, err := range flattenErrors(err) {
+			errs = append(errs, fmt.Errorf("alerts: %w", err))
+		}
+	}
+	if c.Output != "" && !slices.Contains(report.Formats, c.Output) {
+		errs = append(errs, fmt.Errorf("output: must be one of %s", strings.Join(report.Formats, ", ")))
+	}
+	if c.Metrics.Format != "" && !slices.Contains(metricsFormats, c.Metrics.Format) {
+		errs = append(errs, fmt.Errorf("metrics.format: must be one of %s", strings.Join(metricsFormats, ", ")))
+	}
+	if c.Metrics.InfluxURL != "" && !isHTTPURL(c.Metrics.InfluxURL) {
+		errs = append(errs, errors.New("metrics.influx_url: must be an HTTP(S) URL"))
+	}
+	for i, s := range c.Schedules {
+		if !slices.Contains(scheduleCommands, s.Command) {
+			errs = append(errs, fmt.Errorf("schedules[%d].command: must be one of %s", i, strings.Join(scheduleCommands, ", ")))
+		}
+		if _, err := cron.Parse(s.Cron); err != nil {
+			errs = append(errs, fmt.Errorf("schedules[%d].cron: %w", i, err))
+		}
+		if _, err := time.LoadLocation(s.Timezone); err != nil {
+			errs = append(errs, fmt.Errorf("schedules[%d].timezone: %w", i, err))
+		}
+	}
+	names := make(map[string]bool)
+	for i, a := range c.Accounts {
+		switch {
+		case a.Name == "":
+			errs = append(errs, fmt.Errorf("accounts[%d].name: must not be empty", i))
+		case names[a.Name]:
+			errs = append(errs, fmt.Errorf("accounts[%d].name: duplicate name %q", i, a.Name))
+		}
+		names[a.Name] = true
+	}
+	return errs
+}
+
+// validateEnvironment checks the environment variables of the flags that cmd does not have,
+// e.g. EASYBELL_FORECAST_MODEL for config validate.
+// loadConfig only applies the variables of the running command's flags.
+// The values are parsed and checked by the flags of the commands that have them.
+func validateEnvironment(cmd *cobra.Command) (errs []error) {
+	flags := make(map[string]*pflag.Flag)
+	var collect func(c *cobra.Command)
+	collect = func(c *cobra.Command) {
+		c.Flags().VisitAll(func(f *pflag.Flag) {
+			flags[f.Name] = f
+		})
+		for _, sub := range c.Commands() {
+			collect(sub)
+		}
+	}
+	collect(cmd.Root())
+
+	for _, s := range flagEnvironment {
+		flag := flags[s.flag]
+		if flag == nil || cmd.Flags().Lookup(s.flag) != nil {
+			continue
+		}
+		value := os.Getenv(s.env)
+		if s.secret {
+			var err error
+			if value, err = lookupSecretEnv(s.env); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		}
+		if value == "" {
+			continue
+		}
+		if err := flag.Value.Set(value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: invalid value for --%s: %w", s.env, s.flag, err))
+		} else if check := flagChecks[flag]; check != nil {
+			if err := check(); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", s.env, err))
+			}
+		}
+	}
+	return errs
+}
+
+// flagChecks contains the checks of flag values that cannot be done when the flag is parsed, keyed by flag.
+// The checks are run by checkFlags before a command runs and by validateEnvironment for the flags of other commands.
+var flagChecks = make(map[*pflag.Flag]func() error)
+
+// checkFlag registers check as the check of the flag name of cmd.
+func checkFlag(cmd *cobra.Command, name string, check func() error) {
+	flagChecks[cmd.Flags().Lookup(name)] = check
+}
+
+// checkFlags runs the checks of all flags of cmd and returns all errors at once.
+func checkFlags(cmd *cobra.Command) error {
+	var errs []error
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if check := flagChecks[f]; check != nil {
+			errs = append(errs, check())
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// flagValues returns the values of c that correspond to command line flags, keyed by flag name.
+// The values are formatted so that they can be parsed by the respective flag.
+func (c *config) flagValues() map[string]string {
+	values := make(map[string]string)
+	setDuration := func(name string, d *time.Duration) {
+		if d != nil {
+			values[name] = d.String()
+		}
+	}
+	setFloat := func(name string, f *float64) {
+		if f != nil {
+			values[name] = strconv.FormatFloat(*f, 'f', -1, 64)
+		}
+	}
+	setString := func(name string, s string) {
+		if s != "" {
+			values[name] = s
+		}
+	}
+	setDuration("national-minutes", c.Tariff.NationalMinutes)
+	setDuration("mobile-minutes", c.Tariff.MobileMinutes)
+	setFloat("national-price", c.Tariff.NationalPrice)
+	setFloat("mobile-price", c.Tariff.MobilePrice)
+	if c.Billing.Day != nil {
+		values["billing-day"] = strconv.Itoa(*c.Billing.Day)
+	}
+	setString("billing-dates", strings.Join(c.Billing.Dates, ","))
+	setString("timezone", c.Billing.Timezone)
+	if c.Notifications.Teams.Enabled != nil {
+		values["teams-webhook"] = strconv.FormatBool(*c.Notifications.Teams.Enabled)
+	}
+	setString("webhook-url", c.Notifications.Teams.WebhookURL)
+	setString("webhook-type", c.Notifications.Teams.Type)
+	setString("card-templates", c.Notifications.Teams.Templates)
+	setString("model", c.Forecast.Model)
+	setDuration("estimate", c.Forecast.Estimate)
+	setFloat("confidence", c.Forecast.Confidence)
+	setString("holidays", c.Forecast.Holidays)
+	quotas := make([]string, len(c.Alerts.Quotas))
+	for i, q := range c.Alerts.Quotas {
+		quotas[i] = strconv.Itoa(q)
+	}
+	setString("quota-thresholds", strings.Join(quotas, ","))
+	setFloat("cost-threshold", c.Alerts.Cost)
+	setString("state-file", c.Alerts.StateFile)
+	setString("listen", c.Server.Listen)
+	setDuration("cache-ttl", c.Server.CacheTTL)
+	setString("api-tokens", strings.Join(c.Server.APITokens, ","))
+	setString("textfile", c.Metrics.Textfile)
+	setString("influx-url", c.Metrics.InfluxURL)
+	setString("influx-token", c.Metrics.InfluxToken)
+	setString("format", c.Metrics.Format)
+	if c.Summary != nil {
+		values["summary"] = strconv.FormatBool(*c.Summary)
+	}
+	setString("locale", c.Locale)
+	setString("output", c.Output)
+	return values
+}
+
+// loadConfig reads the configuration file, the environment and the flags of cmd
+// and initializes the global settings.
+// All invalid settings are reported at once.
+func loadConfig(cmd *cobra.Command) error {
+	var errs []error
+	file := &config{}
+	if configFile == "" {
+		configFile = os.Getenv("EASYBELL_CONFIG")
+	}
+	if configFile != "" {
+		var err error
+		if file, err = readConfig(configFile); err != nil {
+			errs = append(errs, err)
+		}
+		errs = append(errs, file.validate()...)
+		errs = append(errs, file.resolveSecrets()...)
+	}
+
+	fileValues := file.flagValues()
+	for _, s := range flagEnvironment {
+		flag := cmd.Flags().Lookup(s.flag)
+		if flag == nil || flag.Changed {
+			continue
+		}
+		source, value := s.env, os.Getenv(s.env)
+		if s.secret {
+			var err error
+			if value, err = lookupSecretEnv(s.env); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		}
+		if value == "" {
+			source, value = configFile, fileValues[s.flag]
+		}
+		if value == "" {
+			continue
+		}
+		if err := cmd.Flags().Set(s.flag, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: invalid value for --%s: %w", source, s.flag, err))
+		}
+	}
+
+	var err error
+	if location, err = time.LoadLocation(timezone); err != nil {
+		errs = append(errs, fmt.Errorf("invalid time zone: %w", err))
+	} else if cycle, err = parseBillingCycle(billingDay, billingDates, location); err != nil {
+		errs = append(errs, err)
+	}
+	if localeName != "" {
+		if locale, err = i18n.Lookup(localeName); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	registerSecret(teamsWebhookURL)
+	// Webhook URLs with an explicit type are added to the patterns of the client by allowTeamsWebhook.
+	teamsClient, teamsPatternsAdded = goteamsnotify.NewTeamsClient(), false
+	errs = append(errs, loadAccounts(file)...)
+	schedules = file.Schedules
+	if summary {
+		global := globalNotifications(file.Notifications)
+		for _, err := range global.validate() {
+			errs = append(errs, fmt.Errorf("summary: %w", err))
+		}
+		summaryNotifier, _ = global.notifiers()
+	}
+	return errors.Join(errs...)
+}
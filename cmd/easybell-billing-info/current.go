@@ -1,167 +1,173 @@
 package main
 
 import (
+	"context"
 	"errors"
-	"fmt"
-	"math"
+	"strings"
 	"time"
 
-	"github.com/atc0005/go-teams-notify/v2/adaptivecard"
 	"github.com/spf13/cobra"
 
 	"github.com/lmr-hh/easybell-billing-info/easybell"
+	"github.com/lmr-hh/easybell-billing-info/forecast"
+	"github.com/lmr-hh/easybell-billing-info/holiday"
+	"github.com/lmr-hh/easybell-billing-info/report"
 )
 
-var estimationPeriod time.Duration
+var (
+	estimationPeriod time.Duration
+	modelName        string
+	holidayState     string
+	confidenceLevel  float64
+	model            forecast.Model
+)
 
 func init() {
-	currentMonthCommand.Flags().DurationVarP(&estimationPeriod, "estimate", "e", 35*24*time.Hour, "The number of days to include when estimating the usage until the end of the month.")
+	addForecastFlags(currentMonthCommand)
+	addOutputFlag(currentMonthCommand)
+	addDryRunFlags(currentMonthCommand)
 	rootCommand.AddCommand(currentMonthCommand)
 }
 
+// addForecastFlags adds the flags that configure the forecast to cmd.
+func addForecastFlags(cmd *cobra.Command) {
+	cmd.Flags().DurationVarP(&estimationPeriod, "estimate", "e", 35*24*time.Hour, "The number of days to include when estimating the usage until the end of the billing period.")
+	cmd.Flags().StringVar(&modelName, "model", forecast.ModelLinear, "The forecast model ("+strings.Join(forecast.Models, ", ")+").")
+	cmd.Flags().Float64Var(&confidenceLevel, "confidence", 0.8, "The confidence level of the forecast range.")
+	cmd.Flags().StringVar(&holidayState, "holidays", "", "The federal state whose public holidays are considered by the weekday model (e.g. HH).")
+	checkFlag(cmd, "estimate", func() error { return checkEstimationPeriod(estimationPeriod) })
+	checkFlag(cmd, "model", func() error {
+		_, err := forecast.New(modelName, holiday.Calendar{})
+		return err
+	})
+	checkFlag(cmd, "confidence", func() error {
+		if confidenceLevel <= 0 || confidenceLevel >= 1 {
+			return errors.New("confidence level must be between 0 and 1")
+		}
+		return nil
+	})
+	checkFlag(cmd, "holidays", checkHolidays)
+}
+
+// checkEstimationPeriod checks that the estimation period d is longer than a day.
+func checkEstimationPeriod(d time.Duration) error {
+	if d <= 24*time.Hour {
+		return errors.New("estimation period must be at least 1 day")
+	}
+	return nil
+}
+
+// checkHolidays checks that the federal state of the holidays is known.
+func checkHolidays() error {
+	_, err := holiday.NewCalendar(holidayState)
+	return err
+}
+
+// loadModel initializes the forecast model from the forecast flags.
+func loadModel() error {
+	holidays, err := holiday.NewCalendar(holidayState)
+	if err != nil {
+		return err
+	}
+	model, err = forecast.New(modelName, holidays)
+	return err
+}
+
 var currentMonthCommand = &cobra.Command{
 	Use:   "current-month",
-	Short: "Report the current month's usage and an estimate to the end of the month.",
+	Short: "Report the current billing period's usage and an estimate to the end of the period.",
 	Args:  cobra.NoArgs,
 	PreRunE: func(cmd *cobra.Command, args []string) error {
-		if estimationPeriod <= 24*time.Hour {
-			return errors.New("estimation period must be at least 1 day")
+		if err := loadModel(); err != nil {
+			return err
 		}
-		return nil
+		return loadDryRun()
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		now := time.Now()
-		year, month, _ := now.Date()
-		startOfMonth := time.Date(year, month, 1, 0, 0, 0, 0, time.Local)
-		estimationStart := now.Add(-estimationPeriod)
-		endOfMonth := time.Date(year, month+1, 1, 0, 0, 0, 0, time.Local)
-		fullMonth := endOfMonth.Sub(startOfMonth)
+		return runCurrentMonth(cmd.Context())
+	},
+}
+
+// runCurrentMonth reports the usage of the current billing period of all accounts including a forecast.
+func runCurrentMonth(ctx context.Context) error {
+	out, err := newOutput()
+	if err != nil {
+		return err
+	}
+	now := time.Now().In(location)
+	startOfPeriod, endOfPeriod := cycle.Period(now)
+	reports, errs := currentReports(now, false)
 
-		reader := easybell.NewCallLogReader(client, startOfMonth, endOfMonth)
+	var done []*report.Report
+	for i, a := range accounts {
+		if errs[i] != nil {
+			continue
+		}
+		if err := out.Write(reports[i]); err != nil {
+			errs = append(errs, err)
+		}
+		done = append(done, reports[i])
+		errs[i] = a.wrap(a.Notifier.Notify(ctx, reports[i]))
+	}
+	if summary {
+		s := newSummary(startOfPeriod, endOfPeriod, done)
+		if err := out.Write(s); err != nil {
+			errs = append(errs, err)
+		}
+		errs = append(errs, summaryNotifier.Notify(ctx, s))
+	}
+	return errors.Join(append(errs, out.Close())...)
+}
+
+// currentReports returns the reports of the billing period containing now including a forecast for every account.
+// If withCalls is true, the reports also contain the calls of the period.
+// The reports and errors are returned in the order of accounts.
+func currentReports(now time.Time, withCalls bool) ([]*report.Report, []error) {
+	startOfPeriod, endOfPeriod := cycle.Period(now)
+	estimationStart := now.Add(-estimationPeriod)
+	return forEachAccount(func(a *account, client *easybell.Client) (*report.Report, error) {
+		reader := easybell.NewCallLogReader(client, startOfPeriod, endOfPeriod)
 		reader.Direction = easybell.CallDirectionSuccessfulOutbound
-		currentUsage, err := reader.ReadUsage()
-		if err != nil {
-			return err
+		var currentUsage easybell.Usage
+		var currentCalls []easybell.CallLogEntry
+		var err error
+		if withCalls {
+			if currentCalls, err = reader.ReadAll(); err != nil {
+				return nil, err
+			}
+			for i := range currentCalls {
+				currentUsage.Add(&currentCalls[i])
+			}
+		} else if currentUsage, err = reader.ReadUsage(); err != nil {
+			return nil, err
 		}
 
 		reader.Reset(estimationStart, now)
-		pastUsage, err := reader.ReadUsage()
+		pastCalls, err := reader.ReadAll()
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		factor := fullMonth.Hours() / estimationPeriod.Hours()
-		estimateUsage := easybell.Usage{
-			National: time.Duration(float64(pastUsage.National) * factor),
-			Mobile:   time.Duration(float64(pastUsage.Mobile) * factor),
-			Other:    time.Duration(float64(pastUsage.Other) * factor),
+		estimate := forecast.Evaluate(model, forecast.Input{
+			Start:   startOfPeriod,
+			End:     endOfPeriod,
+			Now:     now,
+			Current: currentUsage,
+			Window:  estimationPeriod,
+			History: forecast.Days(pastCalls, estimationStart, now),
+		}, confidenceLevel, a.Tariff.NationalQuota, a.Tariff.MobileQuota)
+		r := a.newReport(report.KindCurrent, startOfPeriod, endOfPeriod, currentUsage)
+		r.Calls = currentCalls
+		r.Forecast = &report.Forecast{
+			Model:             modelName,
+			Window:            estimationPeriod,
+			Estimate:          estimate.Estimate,
+			Low:               estimate.Low,
+			High:              estimate.High,
+			Level:             estimate.Level,
+			NationalExhausted: estimate.NationalExhausted,
+			MobileExhausted:   estimate.MobileExhausted,
 		}
-
-		printCurrentUsageReport(now, currentUsage, estimateUsage)
-		if !sendWebhook {
-			return nil
-		}
-		return sendCurrentUsageReport(now, currentUsage, estimateUsage)
-	},
-}
-
-func printCurrentUsageReport(now time.Time, currentUsage easybell.Usage, estimateUsage easybell.Usage) {
-	fmt.Printf("EasyBell Usage Report for %s %d\n\n", now.Month().String(), now.Year())
-	fmt.Printf("This Month:\n")
-	printUsage(currentUsage)
-	fmt.Printf("\nEstimated Usage at the End of the Month:\n")
-	printUsage(estimateUsage)
-	fmt.Printf("\nThe estimate is based on the average usage of the last %.1f days.\n", estimationPeriod.Hours()/24)
-}
-
-func sendCurrentUsageReport(now time.Time, currentUsage easybell.Usage, estimateUsage easybell.Usage) error {
-	otherCallsVisible := currentUsage.Other > 0
-	card := adaptivecard.Card{
-		Type:         adaptivecard.TypeAdaptiveCard,
-		Schema:       adaptivecard.AdaptiveCardSchema,
-		Version:      "1.4",
-		FallbackText: "",
-		Body: adaptivecard.Elements{{
-			Type: adaptivecard.TypeElementContainer,
-			Items: adaptivecard.Elements{{
-				Type:   adaptivecard.TypeElementTextBlock,
-				Text:   "easyBell Telefonieverbrauch",
-				Wrap:   true,
-				Weight: adaptivecard.WeightBolder,
-				Size:   adaptivecard.SizeExtraLarge,
-			}, {
-				Type:     adaptivecard.TypeElementTextBlock,
-				Text:     fmt.Sprintf("%s %d", months[now.Month()], now.Year()),
-				Wrap:     true,
-				Spacing:  adaptivecard.SpacingNone,
-				IsSubtle: true,
-				Weight:   adaptivecard.WeightBolder,
-			}},
-		}, {
-			Type:      adaptivecard.TypeElementContainer,
-			Separator: true,
-			Items: adaptivecard.Elements{{
-				Type: adaptivecard.TypeElementColumnSet,
-				Columns: adaptivecard.Columns{
-					makeGaugeElement("Festnetz", formatDuration(currentUsage.National), adaptivecard.HorizontalAlignmentLeft, adaptivecard.WeightDefault, minutesColor(currentUsage.National, NationalQuota, adaptivecard.ColorDefault)),
-					makeGaugeElement("Mobil", formatDuration(currentUsage.Mobile), adaptivecard.HorizontalAlignmentCenter, adaptivecard.WeightDefault, minutesColor(currentUsage.Mobile, MobileQuota, adaptivecard.ColorDefault)),
-					makeGaugeElement("Andere", formatDuration(currentUsage.Other), adaptivecard.HorizontalAlignmentRight, adaptivecard.WeightDefault, minutesColor(currentUsage.Other, 0, adaptivecard.ColorDefault)),
-				},
-			}},
-		}, {
-			Type:      adaptivecard.TypeElementContainer,
-			Separator: true,
-			Items: adaptivecard.Elements{{
-				Type:   adaptivecard.TypeElementTextBlock,
-				Text:   "Prognose zum Monatsende",
-				Size:   adaptivecard.SizeLarge,
-				Weight: adaptivecard.WeightBolder,
-			}, {
-				Type:     adaptivecard.TypeElementTextBlock,
-				Text:     "Diese Daten sind ein Schätzwert für den Telefonverbrauch am Monatsende. Sie beruhen auf den Daten der letzten fünf Wochen.",
-				Wrap:     true,
-				Spacing:  adaptivecard.SpacingNone,
-				Size:     adaptivecard.SizeSmall,
-				IsSubtle: true,
-			}, {
-				Type: adaptivecard.TypeElementColumnSet,
-				Columns: adaptivecard.Columns{
-					makeGaugeElement(fmt.Sprintf("Festnetz (%.0f)", NationalQuota.Minutes()), fmt.Sprintf("%02.0f min.", math.Ceil(estimateUsage.National.Minutes())), adaptivecard.HorizontalAlignmentLeft, adaptivecard.WeightBolder, minutesColor(estimateUsage.National, NationalQuota, adaptivecard.ColorGood)),
-					makeGaugeElement(fmt.Sprintf("Mobil (%.0f)", MobileQuota.Minutes()), fmt.Sprintf("%02.0f min.", math.Ceil(estimateUsage.Mobile.Minutes())), adaptivecard.HorizontalAlignmentCenter, adaptivecard.WeightBolder, minutesColor(estimateUsage.Mobile, MobileQuota, adaptivecard.ColorGood)),
-					makeGaugeElement("Andere", fmt.Sprintf("%02.0f min.", math.Ceil(estimateUsage.Other.Minutes())), adaptivecard.HorizontalAlignmentRight, adaptivecard.WeightBolder, minutesColor(estimateUsage.Other, 0, adaptivecard.ColorGood)),
-				},
-			}, {
-				Type: adaptivecard.TypeElementColumnSet,
-				Columns: adaptivecard.Columns{{
-					Type:  adaptivecard.TypeColumn,
-					Width: adaptivecard.ColumnWidthStretch,
-					Items: []*adaptivecard.Element{{
-						Type:   adaptivecard.TypeElementTextBlock,
-						Text:   "Zusätzliche Kosten",
-						Weight: adaptivecard.WeightBolder,
-					}},
-				}, {
-					Type:  adaptivecard.TypeColumn,
-					Width: adaptivecard.ColumnWidthAuto,
-					Items: []*adaptivecard.Element{{
-						Type:   adaptivecard.TypeElementTextBlock,
-						Text:   fmt.Sprintf("%.2f €", calculateCost(estimateUsage)),
-						Weight: adaptivecard.WeightBolder,
-					}},
-				}},
-			}},
-		}, {
-			Type:    adaptivecard.TypeElementTextBlock,
-			Text:    "Es sind in diesem Zeitraum internationale Anrufe getätigt worden. In der Kostenschätzung sind diese nicht berücksichtigt.",
-			Wrap:    true,
-			Spacing: adaptivecard.SpacingNone,
-			Color:   adaptivecard.ColorWarning,
-			Visible: &otherCallsVisible,
-		}},
-	}
-	if msg, err := adaptivecard.NewMessageFromCard(card); err != nil {
-		return err
-	} else {
-		return teamsClient.Send(teamsWebhookURL, msg)
-	}
+		return r, nil
+	})
 }
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// billingCycle determines the billing periods of an easyBell contract.
+// By default a billing period is a calendar month.
+type billingCycle struct {
+	// AnchorDay is the day of the month on which a new billing period starts.
+	// If a month has fewer days, the period starts on the last day of that month.
+	AnchorDay int
+	// Dates optionally contains explicit start dates of billing periods in ascending order.
+	// Between the first and the last date the periods are determined by Dates.
+	// The period before the first date ends at the first date,
+	// the period starting at the last date ends at the next AnchorDay.
+	// Outside of this range AnchorDay is used.
+	Dates []time.Time
+}
+
+// cycle is the billing cycle used by all commands.
+var cycle = billingCycle{AnchorDay: 1}
+
+// parseBillingCycle creates a billing cycle from an anchor day and a list of explicit dates in the format YYYY-MM-DD.
+func parseBillingCycle(anchorDay int, dates []string, loc *time.Location) (c billingCycle, err error) {
+	if anchorDay < 1 || anchorDay > 31 {
+		return c, fmt.Errorf("invalid billing day %d: must be between 1 and 31", anchorDay)
+	}
+	c.AnchorDay = anchorDay
+	for _, date := range dates {
+		t, err := time.ParseInLocation(time.DateOnly, date, loc)
+		if err != nil {
+			return c, fmt.Errorf("invalid billing date %q: %w", date, err)
+		}
+		c.Dates = append(c.Dates, t)
+	}
+	slices.SortFunc(c.Dates, time.Time.Compare)
+	return c, nil
+}
+
+// Period returns the billing period containing t.
+// The period includes start and excludes end.
+func (c billingCycle) Period(t time.Time) (start, end time.Time) {
+	i, _ := slices.BinarySearchFunc(c.Dates, t, func(date, t time.Time) int {
+		if date.After(t) {
+			return 1
+		}
+		return -1
+	})
+	switch {
+	case len(c.Dates) == 0:
+	case i == 0:
+		start, end = c.anchorPeriod(t)
+		if end.After(c.Dates[0]) {
+			end = c.Dates[0]
+		}
+		return start, end
+	case i < len(c.Dates):
+		return c.Dates[i-1], c.Dates[i]
+	default:
+		last := c.Dates[len(c.Dates)-1]
+		if _, end = c.anchorPeriod(last); t.Before(end) {
+			return last, end
+		}
+	}
+	return c.anchorPeriod(t)
+}
+
+// anchorPeriod returns the billing period containing t that is determined by AnchorDay only.
+func (c billingCycle) anchorPeriod(t time.Time) (start, end time.Time) {
+	year, month, _ := t.Date()
+	start = c.anchor(year, month, t.Location())
+	if start.After(t) {
+		start = c.anchor(year, month-1, t.Location())
+	}
+	return start, c.anchor(start.Year(), start.Month()+1, t.Location())
+}
+
+// Previous returns the billing period before the one containing t.
+func (c billingCycle) Previous(t time.Time) (start, end time.Time) {
+	start, _ = c.Period(t)
+	return c.Period(start.Add(-time.Nanosecond))
+}
+
+// anchor returns the start of the billing period that begins in the specified month.
+func (c billingCycle) anchor(year int, month time.Month, loc *time.Location) time.Time {
+	lastDay := time.Date(year, month+1, 0, 0, 0, 0, 0, loc).Day()
+	return time.Date(year, month, min(c.AnchorDay, lastDay), 0, 0, 0, 0, loc)
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/lmr-hh/easybell-billing-info/easybell"
+)
+
+// berlin returns the time of a wall clock value in the format "2006-01-02 15:04:05" in Europe/Berlin.
+func berlin(t *testing.T, value string) time.Time {
+	t.Helper()
+	v, err := time.ParseInLocation(time.DateTime, value, easybell.Location)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestBillingCyclePeriod(t *testing.T) {
+	tests := []struct {
+		name      string
+		anchorDay int
+		t         string
+		wantStart string
+		wantEnd   string
+	}{
+		{"spring forward", 1, "2026-03-29 03:30:00", "2026-03-01 00:00:00", "2026-04-01 00:00:00"},
+		{"end of month with spring forward", 1, "2026-03-31 23:59:59", "2026-03-01 00:00:00", "2026-04-01 00:00:00"},
+		{"start of month after spring forward", 1, "2026-04-01 00:00:00", "2026-04-01 00:00:00", "2026-05-01 00:00:00"},
+		{"fall back", 1, "2026-10-25 02:30:00", "2026-10-01 00:00:00", "2026-11-01 00:00:00"},
+		{"end of month with fall back", 1, "2026-10-31 23:59:59", "2026-10-01 00:00:00", "2026-11-01 00:00:00"},
+		{"anchor 15 spring forward", 15, "2026-03-29 03:30:00", "2026-03-15 00:00:00", "2026-04-15 00:00:00"},
+		{"anchor 15 before start", 15, "2026-03-14 23:59:59", "2026-02-15 00:00:00", "2026-03-15 00:00:00"},
+		{"anchor 15 fall back", 15, "2026-10-25 02:30:00", "2026-10-15 00:00:00", "2026-11-15 00:00:00"},
+		{"anchor 15 before fall back period", 15, "2026-10-14 23:59:59", "2026-09-15 00:00:00", "2026-10-15 00:00:00"},
+		{"anchor on the day of spring forward", 29, "2026-03-29 00:00:00", "2026-03-29 00:00:00", "2026-04-29 00:00:00"},
+		{"anchor on the day of fall back", 25, "2026-10-25 01:00:00", "2026-10-25 00:00:00", "2026-11-25 00:00:00"},
+		{"anchor after the end of February", 31, "2026-03-01 12:00:00", "2026-02-28 00:00:00", "2026-03-31 00:00:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := billingCycle{AnchorDay: tt.anchorDay}
+			start, end := c.Period(berlin(t, tt.t))
+			if want := berlin(t, tt.wantStart); !start.Equal(want) {
+				t.Errorf("start = %s, want %s", start, want)
+			}
+			if want := berlin(t, tt.wantEnd); !end.Equal(want) {
+				t.Errorf("end = %s, want %s", end, want)
+			}
+		})
+	}
+}
+
+func TestBillingCyclePrevious(t *testing.T) {
+	tests := []struct {
+		name      string
+		anchorDay int
+		t         string
+		wantStart string
+		wantEnd   string
+	}{
+		{"month with spring forward", 1, "2026-04-01 00:00:00", "2026-03-01 00:00:00", "2026-04-01 00:00:00"},
+		{"month with fall back", 1, "2026-11-01 00:00:00", "2026-10-01 00:00:00", "2026-11-01 00:00:00"},
+		{"anchor 15 with spring forward", 15, "2026-04-20 12:00:00", "2026-03-15 00:00:00", "2026-04-15 00:00:00"},
+		{"anchor 15 with fall back", 15, "2026-11-15 00:00:00", "2026-10-15 00:00:00", "2026-11-15 00:00:00"},
+		{"anchor 15 in the repeated hour", 15, "2026-10-25 02:30:00", "2026-09-15 00:00:00", "2026-10-15 00:00:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := billingCycle{AnchorDay: tt.anchorDay}
+			start, end := c.Previous(berlin(t, tt.t))
+			if want := berlin(t, tt.wantStart); !start.Equal(want) {
+				t.Errorf("start = %s, want %s", start, want)
+			}
+			if want := berlin(t, tt.wantEnd); !end.Equal(want) {
+				t.Errorf("end = %s, want %s", end, want)
+			}
+		})
+	}
+}
+
+// TestBillingCyclePeriodOfCalls checks that calls around midnight at the start of a period are assigned to the right period.
+func TestBillingCyclePeriodOfCalls(t *testing.T) {
+	tests := []struct {
+		anchorDay int
+		datum     string
+		wantStart string
+	}{
+		{1, "31.03.2026 23:59:59", "2026-03-01 00:00:00"},
+		{1, "01.04.2026 00:00:00", "2026-04-01 00:00:00"},
+		{1, "31.10.2026 23:59:59", "2026-10-01 00:00:00"},
+		{1, "01.11.2026 00:00:00", "2026-11-01 00:00:00"},
+		{1, "28.02.2026 23:59:59", "2026-02-01 00:00:00"},
+		{1, "01.03.2026 00:00:00", "2026-03-01 00:00:00"},
+		{15, "14.04.2026 23:59:59", "2026-03-15 00:00:00"},
+		{15, "15.04.2026 00:00:00", "2026-04-15 00:00:00"},
+		{15, "14.11.2026 23:59:59", "2026-10-15 00:00:00"},
+		{15, "15.11.2026 00:00:00", "2026-11-15 00:00:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.datum, func(t *testing.T) {
+			var e easybell.CallLogEntry
+			if err := json.Unmarshal([]byte(`{"DATUM": "`+tt.datum+`"}`), &e); err != nil {
+				t.Fatal(err)
+			}
+			c := billingCycle{AnchorDay: tt.anchorDay}
+			start, end := c.Period(e.Time)
+			if want := berlin(t, tt.wantStart); !start.Equal(want) {
+				t.Errorf("start = %s, want %s", start, want)
+			}
+			if e.Time.Before(start) || !e.Time.Before(end) {
+				t.Errorf("%s is not in the period from %s to %s", e.Time, start, end)
+			}
+		})
+	}
+}
+
+func TestBillingCycleDates(t *testing.T) {
+	c := billingCycle{AnchorDay: 1, Dates: []time.Time{berlin(t, "2025-01-15 00:00:00"), berlin(t, "2025-02-14 00:00:00")}}
+	tests := []struct {
+		name      string
+		t         string
+		wantStart string
+		wantEnd   string
+	}{
+		{"before the anchor period of the first date", "2024-12-20 12:00:00", "2024-12-01 00:00:00", "2025-01-01 00:00:00"},
+		{"before the first date", "2025-01-10 12:00:00", "2025-01-01 00:00:00", "2025-01-15 00:00:00"},
+		{"at the first date", "2025-01-15 00:00:00", "2025-01-15 00:00:00", "2025-02-14 00:00:00"},
+		{"before the last date", "2025-02-13 23:59:59", "2025-01-15 00:00:00", "2025-02-14 00:00:00"},
+		{"at the last date", "2025-02-14 00:00:00", "2025-02-14 00:00:00", "2025-03-01 00:00:00"},
+		{"after the last date", "2025-02-20 12:00:00", "2025-02-14 00:00:00", "2025-03-01 00:00:00"},
+		{"after the period of the last date", "2025-03-01 00:00:00", "2025-03-01 00:00:00", "2025-04-01 00:00:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := c.Period(berlin(t, tt.t))
+			if want := berlin(t, tt.wantStart); !start.Equal(want) {
+				t.Errorf("start = %s, want %s", start, want)
+			}
+			if want := berlin(t, tt.wantEnd); !end.Equal(want) {
+				t.Errorf("end = %s, want %s", end, want)
+			}
+		})
+	}
+
+	// The periods returned by Previous must be adjacent and must not overlap.
+	start, end := c.Period(berlin(t, "2025-04-10 00:00:00"))
+	for range 5 {
+		previousStart, previousEnd := c.Previous(start)
+		if !previousEnd.Equal(start) || !previousStart.Before(previousEnd) {
+			t.Errorf("Previous(%s) = %s – %s, want a period ending at %s", start, previousStart, previousEnd, start)
+		}
+		start, end = previousStart, previousEnd
+	}
+	if want := berlin(t, "2024-12-01 00:00:00"); !start.Equal(want) || !end.Equal(berlin(t, "2025-01-01 00:00:00")) {
+		t.Errorf("period 5 before April 2025 = %s – %s, want December 2024", start, end)
+	}
+}
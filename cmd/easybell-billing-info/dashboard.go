@@ -0,0 +1,113 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/lmr-hh/easybell-billing-info/dashboard"
+	"github.com/lmr-hh/easybell-billing-info/easybell"
+	"github.com/lmr-hh/easybell-billing-info/report"
+)
+
+// historyPeriods is the number of completed billing periods shown in the dashboard.
+const historyPeriods = 12
+
+// history caches the reports of the completed billing periods shown in the dashboard.
+var history = &historyCache{}
+
+// historyCache caches the reports of completed billing periods of all accounts.
+// The usage of completed periods does not change, so it is only fetched again when a new period starts.
+// Failed fetches are retried after the ttl of the usage cache.
+type historyCache struct {
+	mu sync.Mutex
+	// start is the start of the billing period in which the reports were fetched.
+	start   time.Time
+	fetched time.Time
+	reports [][]*report.Report
+	errs    []error
+}
+
+// get returns the reports of the completed billing periods before now and the errors in the order of accounts.
+func (c *historyCache) get(now time.Time) ([][]*report.Report, []error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	start, _ := cycle.Period(now)
+	failed := false
+	for _, err := range c.errs {
+		failed = failed || err != nil
+	}
+	if !c.start.Equal(start) || (failed && time.Since(c.fetched) >= cache.ttl) {
+		c.start, c.fetched = start, time.Now()
+		c.reports, c.errs = fetchHistory(now)
+	}
+	return c.reports, c.errs
+}
+
+// fetchHistory fetches the reports of the last completed billing periods before now in chronological order.
+// Errors are logged.
+func fetchHistory(now time.Time) ([][]*report.Report, []error) {
+	periods := make([][2]time.Time, historyPeriods)
+	t := now
+	for i := len(periods) - 1; i >= 0; i-- {
+		start, end := cycle.Previous(t)
+		periods[i] = [2]time.Time{start, end}
+		t = start
+	}
+	reports, errs := forEachAccount(func(a *account, client *easybell.Client) ([]*report.Report, error) {
+		reader := easybell.NewCallLogReader(client, periods[0][0], periods[len(periods)-1][1])
+		reader.Direction = easybell.CallDirectionSuccessfulOutbound
+		calls, err := reader.ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		usage := make([]easybell.Usage, len(periods))
+		for i := range calls {
+			for j, p := range periods {
+				if !calls[i].Time.Before(p[0]) && calls[i].Time.Before(p[1]) {
+					usage[j].Add(&calls[i])
+					break
+				}
+			}
+		}
+		reports := make([]*report.Report, len(periods))
+		for i, p := range periods {
+			reports[i] = a.newReport(report.KindPrevious, p[0], p[1], usage[i])
+		}
+		return reports, nil
+	})
+	for _, err := range errs {
+		if err != nil {
+			log.Printf("could not fetch the usage of the last %d billing periods: %v", historyPeriods, redact(err))
+		}
+	}
+	return reports, errs
+}
+
+// handleDashboard serves the data of the dashboard.
+// The usage of the current billing period is served from the cache.
+// If the completed billing periods of an account cannot be fetched, they are omitted.
+func handleDashboard(w http.ResponseWriter, r *http.Request) {
+	s := cache.get()
+	reports, errs := history.get(time.Now().In(location))
+	d := dashboard.Data{Time: s.Time, Labels: dashboard.Labels(locale), Accounts: []dashboard.Account{}}
+	for i, a := range accounts {
+		current := s.Accounts[i].Report
+		if current == nil {
+			writeError(w, http.StatusBadGateway, a.wrap(errFetchUsage))
+			return
+		}
+		var periods []*report.Report
+		if errs[i] == nil {
+			periods = reports[i]
+		}
+		view, err := dashboard.NewAccount(current, periods, locale)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		d.Accounts = append(d.Accounts, view)
+	}
+	writeJSON(w, http.StatusOK, d)
+}
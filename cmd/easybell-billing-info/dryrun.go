@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/lmr-hh/easybell-billing-info/notify"
+)
+
+var (
+	dryRun    bool
+	dryRunDir string
+)
+
+// addDryRunFlags adds the flags that write the payloads of notifications instead of sending them to cmd.
+func addDryRunFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Write the payloads of all notifications to standard error instead of sending them.")
+	cmd.Flags().StringVar(&dryRunDir, "dry-run-dir", "", "Write the payloads of all notifications as files into this directory instead of sending them. Implies --dry-run.")
+}
+
+// loadDryRun replaces the notifiers of all accounts and of the summary
+// by notifiers that write their payloads if a dry run is requested.
+func loadDryRun() error {
+	dryRun = dryRun || dryRunDir != ""
+	if !dryRun {
+		return nil
+	}
+	if dryRunDir != "" {
+		if err := os.MkdirAll(dryRunDir, 0o755); err != nil {
+			return fmt.Errorf("invalid dry run directory: %w", err)
+		}
+	}
+	for _, a := range accounts {
+		a.Notifier = dryRunNotifiers(a.Notifier)
+	}
+	summaryNotifier = dryRunNotifiers(summaryNotifier)
+	return nil
+}
+
+// dryRunNotifiers returns notifiers that write the payloads of the notifiers in m.
+// All notifiers that are created from the configuration implement [notify.Renderer].
+// Without a directory the payloads are written to standard error so that they do not mix with the reports on standard output.
+func dryRunNotifiers(m notify.Multi) notify.Multi {
+	dry := make(notify.Multi, len(m))
+	for i, n := range m {
+		dry[i] = &notify.DryRun{Renderer: n.(notify.Renderer), Dir: dryRunDir, Out: os.Stderr}
+	}
+	return dry
+}
@@ -1,11 +1,15 @@
 package main
 
 import (
+	"fmt"
 	"os"
 )
 
 func main() {
+	// Errors are printed here so that secrets can be removed from the messages.
+	rootCommand.SilenceErrors = true
 	if err := rootCommand.Execute(); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "Error:", redact(err))
 		os.Exit(1)
 	}
 }
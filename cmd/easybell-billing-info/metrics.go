@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/lmr-hh/easybell-billing-info/metrics"
+)
+
+// These constants identify the formats in which the metrics command writes to standard output.
+const (
+	formatPrometheus = "prometheus"
+	formatInflux     = "influx"
+)
+
+// metricsFormats contains the valid values of the --format flag of the metrics command.
+var metricsFormats = []string{formatPrometheus, formatInflux}
+
+var (
+	textfile      string
+	influxURL     string
+	influxToken   string
+	metricsFormat string
+)
+
+func init() {
+	addForecastFlags(metricsCommand)
+	addMetricsFlags(metricsCommand)
+	rootCommand.AddCommand(metricsCommand)
+}
+
+// addMetricsFlags adds the flags that configure the destinations of the metrics to cmd.
+func addMetricsFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&textfile, "textfile", "", "The .prom file to which the metrics are written for the textfile collector of the node exporter.")
+	cmd.Flags().StringVar(&influxURL, "influx-url", "", "The InfluxDB write endpoint to which the metrics are sent in the line protocol.")
+	cmd.Flags().StringVar(&influxToken, "influx-token", "", "The API token of the InfluxDB write endpoint.")
+	cmd.Flags().StringVar(&metricsFormat, "format", formatInflux, "The format in which the metrics are written to standard output if no destination is set ("+strings.Join(metricsFormats, ", ")+").")
+	checkFlag(cmd, "influx-url", func() error {
+		if influxURL != "" && !isHTTPURL(influxURL) {
+			return errors.New("invalid InfluxDB URL: must be an HTTP(S) URL")
+		}
+		return nil
+	})
+	checkFlag(cmd, "format", func() error {
+		if !slices.Contains(metricsFormats, metricsFormat) {
+			return fmt.Errorf("invalid format: must be one of %s", strings.Join(metricsFormats, ", "))
+		}
+		return nil
+	})
+}
+
+// registerMetricsSecrets registers the credentials of the metrics destinations as secrets.
+func registerMetricsSecrets() {
+	registerSecret(influxURL)
+	registerSecret(influxToken)
+}
+
+var metricsCommand = &cobra.Command{
+	Use:   "metrics",
+	Short: "Write the current billing period's usage metrics to a textfile, InfluxDB or standard output.",
+	Args:  cobra.NoArgs,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		registerMetricsSecrets()
+		return loadModel()
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runMetrics(cmd.Context())
+	},
+}
+
+// runMetrics fetches the usage of the current billing period of all accounts
+// and writes it to the configured destinations.
+// If no destination is configured, the metrics are written to standard output.
+// Metrics are written even if the usage of some accounts could not be fetched.
+func runMetrics(ctx context.Context) error {
+	s := fetchSnapshot()
+	m := s.Metrics()
+	var errs []error
+	if textfile != "" {
+		if err := metrics.WriteTextfile(textfile, m); err != nil {
+			errs = append(errs, fmt.Errorf("could not write textfile: %w", err))
+		}
+	}
+	if influxURL != "" {
+		if err := metrics.PostInflux(ctx, influxURL, influxToken, m, s.Time); err != nil {
+			errs = append(errs, fmt.Errorf("could not send metrics to InfluxDB: %w", err))
+		}
+	}
+	if textfile == "" && influxURL == "" {
+		var err error
+		if metricsFormat == formatPrometheus {
+			err = metrics.WritePrometheus(os.Stdout, m)
+		} else {
+			err = metrics.WriteInflux(os.Stdout, m, s.Time)
+		}
+		errs = append(errs, err)
+	}
+	for _, a := range s.Accounts {
+		if a.Report == nil {
+			// The errors have already been logged by fetchSnapshot.
+			errs = append(errs, errors.New("could not fetch the usage of all accounts"))
+			break
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -1,125 +1,87 @@
 package main
 
 import (
-	"fmt"
-	"math"
+	"context"
+	"errors"
 	"time"
 
-	"github.com/atc0005/go-teams-notify/v2/adaptivecard"
 	"github.com/spf13/cobra"
 
 	"github.com/lmr-hh/easybell-billing-info/easybell"
+	"github.com/lmr-hh/easybell-billing-info/report"
 )
 
 func init() {
+	addOutputFlag(lastMonthCommand)
+	addDryRunFlags(lastMonthCommand)
 	rootCommand.AddCommand(lastMonthCommand)
 }
 
-// lastMonthCommand implements reporting the usage of the past month.
+// lastMonthCommand implements reporting the usage of the previous billing period.
 var lastMonthCommand = &cobra.Command{
 	Use:   "last-month",
-	Short: "Report the previous month's usage.",
+	Short: "Report the previous billing period's usage.",
 	Args:  cobra.NoArgs,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		return loadDryRun()
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		now := time.Now()
-		year, month, _ := now.Date()
-		start := time.Date(year, month-1, 1, 0, 0, 0, 0, time.Local)
-		end := start.AddDate(0, 1, 0)
+		return runLastMonth(cmd.Context())
+	},
+}
 
+// runLastMonth reports the usage of the previous billing period of all accounts.
+func runLastMonth(ctx context.Context) error {
+	out, err := newOutput()
+	if err != nil {
+		return err
+	}
+	start, end := cycle.Previous(time.Now().In(location))
+
+	results, errs := forEachAccount(func(a *account, client *easybell.Client) (previousUsageResult, error) {
 		reader := easybell.NewCallLogReader(client, start, end)
 		reader.Direction = easybell.CallDirectionSuccessfulOutbound
-		usage, err := reader.ReadUsage()
+		if !a.Notifications.needsCalls() {
+			usage, err := reader.ReadUsage()
+			return previousUsageResult{usage: usage}, err
+		}
+		calls, err := reader.ReadAll()
 		if err != nil {
-			return err
+			return previousUsageResult{}, err
 		}
-
-		printPreviousUsageReport(start, usage)
-		if !sendWebhook {
-			return nil
+		var usage easybell.Usage
+		for i := range calls {
+			usage.Add(&calls[i])
 		}
-		return sendPreviousUsageReport(start, usage)
-	},
-}
-
-// printPreviousUsageReport prints the usage of the past month to the command line.
-func printPreviousUsageReport(when time.Time, usage easybell.Usage) {
-	fmt.Printf("EasyBell Usage Report for %s %d\n\n", when.Month().String(), when.Year())
-	printUsage(usage)
-}
+		return previousUsageResult{usage, calls}, nil
+	})
 
-// sendPreviousUsageReport sends a teams message with the usage of the past month.
-func sendPreviousUsageReport(when time.Time, usage easybell.Usage) error {
-	otherCallsVisible := usage.Other > 0
-	card := adaptivecard.Card{
-		Type:         adaptivecard.TypeAdaptiveCard,
-		Schema:       adaptivecard.AdaptiveCardSchema,
-		Version:      "1.4",
-		FallbackText: "",
-		Body: adaptivecard.Elements{{
-			Type: adaptivecard.TypeElementContainer,
-			Items: adaptivecard.Elements{{
-				Type:   adaptivecard.TypeElementTextBlock,
-				Text:   "easyBell Monatsübersicht",
-				Weight: adaptivecard.WeightBolder,
-				Size:   adaptivecard.SizeExtraLarge,
-			}, {
-				Type:     adaptivecard.TypeElementTextBlock,
-				Text:     fmt.Sprintf("%s %d", months[when.Month()], when.Year()),
-				Spacing:  adaptivecard.SpacingNone,
-				IsSubtle: true,
-				Weight:   adaptivecard.WeightBolder,
-			}},
-		}, {
-			Type:      adaptivecard.TypeElementContainer,
-			Separator: true,
-			Items: adaptivecard.Elements{{
-				Type: adaptivecard.TypeElementColumnSet,
-				Columns: adaptivecard.Columns{
-					makeGaugeElement(fmt.Sprintf("Festnetz (%.0f)", NationalQuota.Minutes()), fmt.Sprintf("%.0f min.", math.Ceil(usage.National.Minutes())), adaptivecard.HorizontalAlignmentLeft, adaptivecard.WeightBolder, minutesColor(usage.National, NationalQuota, adaptivecard.ColorGood)),
-					makeGaugeElement(fmt.Sprintf("Mobil (%.0f)", MobileQuota.Minutes()), fmt.Sprintf("%.0f min.", math.Ceil(usage.Mobile.Minutes())), adaptivecard.HorizontalAlignmentCenter, adaptivecard.WeightBolder, minutesColor(usage.Mobile, MobileQuota, adaptivecard.ColorGood)),
-					makeGaugeElement("Andere", fmt.Sprintf("%.0f min.", math.Ceil(usage.Other.Minutes())), adaptivecard.HorizontalAlignmentRight, adaptivecard.WeightBolder, minutesColor(usage.Other, 0, adaptivecard.ColorGood)),
-				},
-			}, {
-				Type: adaptivecard.TypeElementColumnSet,
-				Columns: adaptivecard.Columns{{
-					Type:  adaptivecard.TypeColumn,
-					Width: adaptivecard.ColumnWidthStretch,
-					Items: []*adaptivecard.Element{{
-						Type:   adaptivecard.TypeElementTextBlock,
-						Text:   "Zusätzliche Kosten",
-						Weight: adaptivecard.WeightBolder,
-					}},
-				}, {
-					Type:  adaptivecard.TypeColumn,
-					Width: adaptivecard.ColumnWidthAuto,
-					Items: []*adaptivecard.Element{{
-						Type:   adaptivecard.TypeElementTextBlock,
-						Text:   fmt.Sprintf("%.2f €", calculateCost(usage)),
-						Weight: adaptivecard.WeightBolder,
-					}},
-				}},
-			}},
-		}, {
-			Type:    adaptivecard.TypeElementTextBlock,
-			Text:    "Es sind in diesem Zeitraum internationale Anrufe getätigt worden. In der Kostenschätzung sind diese nicht berücksichtigt.",
-			Wrap:    true,
-			Spacing: adaptivecard.SpacingNone,
-			Color:   adaptivecard.ColorWarning,
-			Visible: &otherCallsVisible,
-		}, {
-			Type:      adaptivecard.TypeElementContainer,
-			Separator: true,
-			Items: adaptivecard.Elements{{
-				Type: adaptivecard.TypeElementTextBlock,
-				Text: "Diese Angaben sind Schätzwerte auf Basis der Anrufliste. Diese Angaben sollten mit dem Einzelverbindungsnachweis, bzw. der Rechnung des Monats abgeglichen werden.",
-				Wrap: true,
-				Size: adaptivecard.SizeSmall,
-			}},
-		}},
+	var reports []*report.Report
+	for i, a := range accounts {
+		if errs[i] != nil {
+			continue
+		}
+		r := a.newReport(report.KindPrevious, start, end, results[i].usage)
+		r.Calls = results[i].calls
+		if err := out.Write(r); err != nil {
+			errs = append(errs, err)
+		}
+		reports = append(reports, r)
+		errs[i] = a.wrap(a.Notifier.Notify(ctx, r))
 	}
-	if msg, err := adaptivecard.NewMessageFromCard(card); err != nil {
-		return err
-	} else {
-		return teamsClient.Send(teamsWebhookURL, msg)
+	if summary {
+		s := newSummary(start, end, reports)
+		if err := out.Write(s); err != nil {
+			errs = append(errs, err)
+		}
+		errs = append(errs, summaryNotifier.Notify(ctx, s))
 	}
+	return errors.Join(append(errs, out.Close())...)
+}
+
+// previousUsageResult is the usage of an account in the previous billing period.
+// The individual calls are only included if a notifier of the account requires them.
+type previousUsageResult struct {
+	usage easybell.Usage
+	calls []easybell.CallLogEntry
 }
@@ -0,0 +1,438 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	goteamsnotify "github.com/atc0005/go-teams-notify/v2"
+
+	"github.com/lmr-hh/easybell-billing-info/notify"
+	"github.com/lmr-hh/easybell-billing-info/report"
+)
+
+// notifications contains the notification targets of an account.
+// Every target can be enabled individually.
+type notifications struct {
+	Teams      teamsTarget
+	Slack      webhookTarget
+	Mattermost webhookTarget
+	Discord    webhookTarget
+	Matrix     matrixTarget
+	Ntfy       ntfyTarget
+	Gotify     gotifyTarget
+	Email      emailTarget
+	Webhook    jsonWebhookTarget
+}
+
+// webhookTarget is a webhook that receives reports.
+type webhookTarget struct {
+	Enabled    bool
+	WebhookURL string
+}
+
+// teamsTarget is a Teams webhook that receives reports.
+// Type is one of [notify.TeamsWebhookTypes].
+type teamsTarget struct {
+	webhookTarget
+	Type      string
+	Templates notify.CardTemplates
+}
+
+// matrixTarget is a Matrix room that receives reports.
+type matrixTarget struct {
+	Enabled bool
+	notify.Matrix
+}
+
+// ntfyTarget is an ntfy topic that receives alerts.
+type ntfyTarget struct {
+	Enabled bool
+	notify.Ntfy
+}
+
+// gotifyTarget is a Gotify server that receives alerts.
+type gotifyTarget struct {
+	Enabled bool
+	notify.Gotify
+}
+
+// emailTarget is an SMTP server and the recipients that receive reports.
+type emailTarget struct {
+	Enabled bool
+	notify.Email
+}
+
+// jsonWebhookTarget is an HTTP endpoint that receives report documents as JSON.
+type jsonWebhookTarget struct {
+	Enabled bool
+	notify.Webhook
+}
+
+// summaryNotifier sends combined summaries to the global notification targets.
+var summaryNotifier notify.Multi
+
+// globalNotifications returns the global notification targets.
+// The Teams webhook is configured via flags, environment variables and the configuration file,
+// all other targets only via the global section c of the configuration file.
+func globalNotifications(c notificationsConfig) notifications {
+	return notifications{
+		Teams: teamsTarget{
+			webhookTarget: webhookTarget{Enabled: sendWebhook, WebhookURL: teamsWebhookURL},
+			Type:          teamsWebhookType,
+			Templates:     notify.CardTemplates{Dir: cardTemplates},
+		},
+		Slack:      webhookTarget{}.merge(c.Slack),
+		Mattermost: webhookTarget{}.merge(c.Mattermost),
+		Discord:    webhookTarget{}.merge(c.Discord),
+		Matrix:     matrixTarget{}.merge(c.Matrix),
+		Ntfy: ntfyTarget{
+			Ntfy: notify.Ntfy{Server: notify.DefaultNtfyServer, MinStatus: report.StatusWarning},
+		}.merge(c.Ntfy),
+		Gotify: gotifyTarget{
+			Gotify: notify.Gotify{MinStatus: report.StatusWarning},
+		}.merge(c.Gotify),
+		Email: emailTarget{}.merge(c.Email),
+		Webhook: jsonWebhookTarget{
+			Webhook: notify.Webhook{Retries: 3, RetryDelay: time.Second},
+		}.merge(c.Webhook),
+	}
+}
+
+// merge returns n with the values that are set in c replaced.
+func (n notifications) merge(c notificationsConfig) notifications {
+	if c.Teams.Enabled != nil {
+		n.Teams.Enabled = *c.Teams.Enabled
+	}
+	if c.Teams.WebhookURL != "" {
+		n.Teams.WebhookURL = c.Teams.WebhookURL
+	}
+	if c.Teams.Type != "" {
+		n.Teams.Type = c.Teams.Type
+	}
+	if c.Teams.Templates != "" {
+		n.Teams.Templates.Dir = c.Teams.Templates
+	}
+	n.Slack = n.Slack.merge(c.Slack)
+	n.Mattermost = n.Mattermost.merge(c.Mattermost)
+	n.Discord = n.Discord.merge(c.Discord)
+	n.Matrix = n.Matrix.merge(c.Matrix)
+	n.Ntfy = n.Ntfy.merge(c.Ntfy)
+	n.Gotify = n.Gotify.merge(c.Gotify)
+	n.Email = n.Email.merge(c.Email)
+	n.Webhook = n.Webhook.merge(c.Webhook)
+	return n
+}
+
+// merge returns t with the values that are set in c replaced.
+// Setting a webhook URL enables the target unless it is disabled explicitly.
+func (t webhookTarget) merge(c webhookConfig) webhookTarget {
+	if c.WebhookURL != "" {
+		t.WebhookURL = c.WebhookURL
+		t.Enabled = true
+	}
+	if c.Enabled != nil {
+		t.Enabled = *c.Enabled
+	}
+	return t
+}
+
+// merge returns t with the values that are set in c replaced.
+// Setting a homeserver enables the target unless it is disabled explicitly.
+func (t matrixTarget) merge(c matrixConfig) matrixTarget {
+	if c.Homeserver != "" {
+		t.Homeserver = c.Homeserver
+		t.Enabled = true
+	}
+	if c.AccessToken != "" {
+		t.AccessToken = c.AccessToken
+	}
+	if c.RoomID != "" {
+		t.RoomID = c.RoomID
+	}
+	if c.Enabled != nil {
+		t.Enabled = *c.Enabled
+	}
+	return t
+}
+
+// merge returns t with the values that are set in c replaced.
+// Setting a topic enables the target unless it is disabled explicitly.
+func (t ntfyTarget) merge(c ntfyConfig) ntfyTarget {
+	if c.Server != "" {
+		t.Server = c.Server
+	}
+	if c.Topic != "" {
+		t.Topic = c.Topic
+		t.Enabled = true
+	}
+	if c.Token != "" {
+		t.Token = c.Token
+	}
+	if c.ClickURL != "" {
+		t.Click = c.ClickURL
+	}
+	if c.MinStatus != nil {
+		t.MinStatus = *c.MinStatus
+	}
+	if c.Enabled != nil {
+		t.Enabled = *c.Enabled
+	}
+	return t
+}
+
+// merge returns t with the values that are set in c replaced.
+// Setting a URL enables the target unless it is disabled explicitly.
+func (t gotifyTarget) merge(c gotifyConfig) gotifyTarget {
+	if c.URL != "" {
+		t.URL = c.URL
+		t.Enabled = true
+	}
+	if c.Token != "" {
+		t.Token = c.Token
+	}
+	if c.ClickURL != "" {
+		t.Click = c.ClickURL
+	}
+	if c.MinStatus != nil {
+		t.MinStatus = *c.MinStatus
+	}
+	if c.Enabled != nil {
+		t.Enabled = *c.Enabled
+	}
+	return t
+}
+
+// merge returns t with the values that are set in c replaced.
+// Setting an SMTP host enables the target unless it is disabled explicitly.
+func (t emailTarget) merge(c emailConfig) emailTarget {
+	if c.Host != "" {
+		t.Host = c.Host
+		t.Enabled = true
+	}
+	if c.Port != 0 {
+		t.Port = c.Port
+	}
+	if c.Security != "" {
+		t.Security = c.Security
+	}
+	if c.Username != "" {
+		t.Username = c.Username
+	}
+	if c.Password != "" {
+		t.Password = c.Password
+	}
+	if c.From != "" {
+		t.From = c.From
+	}
+	if len(c.To) > 0 {
+		t.To = c.To
+	}
+	if c.AttachCalls != nil {
+		t.AttachCalls = *c.AttachCalls
+	}
+	if c.Enabled != nil {
+		t.Enabled = *c.Enabled
+	}
+	return t
+}
+
+// merge returns t with the values that are set in c replaced.
+// Setting a URL enables the target unless it is disabled explicitly.
+func (t jsonWebhookTarget) merge(c jsonWebhookConfig) jsonWebhookTarget {
+	if c.URL != "" {
+		t.URL = c.URL
+		t.Enabled = true
+	}
+	if c.Secret != "" {
+		t.Secret = c.Secret
+	}
+	if c.Retries != nil {
+		t.Retries = *c.Retries
+	}
+	if c.RetryDelay != nil {
+		t.RetryDelay = *c.RetryDelay
+	}
+	if c.Enabled != nil {
+		t.Enabled = *c.Enabled
+	}
+	return t
+}
+
+// validate checks the settings of all enabled targets in n.
+func (n notifications) validate() (errs []error) {
+	if n.Teams.Enabled {
+		if err := validateTeamsWebhook(n.Teams.WebhookURL, n.Teams.Type); err != nil {
+			errs = append(errs, err)
+		}
+		if err := n.Teams.Templates.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("invalid card templates: %w", err))
+		}
+	}
+	if n.Slack.Enabled {
+		if err := validateWebhookURL("Slack", n.Slack.WebhookURL); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if n.Mattermost.Enabled {
+		if err := validateWebhookURL("Mattermost", n.Mattermost.WebhookURL); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if n.Discord.Enabled {
+		if err := validateWebhookURL("Discord", n.Discord.WebhookURL); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if n.Matrix.Enabled {
+		errs = append(errs, n.Matrix.validate()...)
+	}
+	if n.Ntfy.Enabled {
+		if !isHTTPURL(n.Ntfy.Server) {
+			errs = append(errs, errors.New("ntfy: invalid server URL"))
+		}
+		if n.Ntfy.Topic == "" {
+			errs = append(errs, errors.New("ntfy: no topic specified"))
+		}
+	}
+	if n.Gotify.Enabled {
+		if !isHTTPURL(n.Gotify.URL) {
+			errs = append(errs, errors.New("gotify: invalid server URL"))
+		}
+		if n.Gotify.Token == "" {
+			errs = append(errs, errors.New("gotify: no application token specified"))
+		}
+	}
+	if n.Email.Enabled {
+		errs = append(errs, n.Email.validate()...)
+	}
+	if n.Webhook.Enabled {
+		if err := validateWebhookURL("JSON", n.Webhook.URL); err != nil {
+			errs = append(errs, err)
+		}
+		if n.Webhook.Retries < 0 {
+			errs = append(errs, errors.New("webhook: retries must not be negative"))
+		}
+		if n.Webhook.RetryDelay < 0 {
+			errs = append(errs, errors.New("webhook: retry delay must not be negative"))
+		}
+	}
+	return errs
+}
+
+// validate checks the settings of t.
+func (t matrixTarget) validate() (errs []error) {
+	if !isHTTPURL(t.Homeserver) {
+		errs = append(errs, errors.New("matrix: invalid homeserver URL"))
+	}
+	if t.AccessToken == "" {
+		errs = append(errs, errors.New("matrix: no access token specified"))
+	}
+	if !strings.HasPrefix(t.RoomID, "!") || !strings.Contains(t.RoomID, ":") {
+		errs = append(errs, fmt.Errorf("matrix: invalid room ID %q", t.RoomID))
+	}
+	return errs
+}
+
+// validate checks the settings of t.
+func (t emailTarget) validate() (errs []error) {
+	if t.Host == "" {
+		errs = append(errs, errors.New("email: no SMTP host specified"))
+	}
+	if t.Port < 0 || t.Port > 65535 {
+		errs = append(errs, fmt.Errorf("email: invalid port %d", t.Port))
+	}
+	if t.Security != "" && !slices.Contains(notify.EmailSecurityModes, t.Security) {
+		errs = append(errs, fmt.Errorf("email: security must be one of %s", strings.Join(notify.EmailSecurityModes, ", ")))
+	}
+	if _, err := mail.ParseAddress(t.From); err != nil {
+		errs = append(errs, fmt.Errorf("email: invalid sender: %w", err))
+	}
+	if len(t.To) == 0 {
+		errs = append(errs, errors.New("email: no recipients specified"))
+	}
+	for _, to := range t.To {
+		if _, err := mail.ParseAddress(to); err != nil {
+			errs = append(errs, fmt.Errorf("email: invalid recipient %q: %w", to, err))
+		}
+	}
+	return errs
+}
+
+// allowTeamsWebhook adds the origin of webhookURL to the URL patterns of teamsClient
+// if the kind of the webhook is set explicitly and the URL does not match the patterns of goteamsnotify,
+// e.g. for workflows in sovereign clouds.
+func allowTeamsWebhook(webhookURL, kind string) {
+	if kind == notify.TeamsAuto || teamsClient.ValidateWebhook(webhookURL) == nil {
+		return
+	}
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return
+	}
+	if !teamsPatternsAdded {
+		// The default patterns only apply as long as no patterns are added.
+		teamsClient.AddWebhookURLValidationPatterns(goteamsnotify.DefaultWebhookURLValidationPattern, goteamsnotify.WorkflowURLBaseDomain)
+		teamsPatternsAdded = true
+	}
+	teamsClient.AddWebhookURLValidationPatterns("^" + regexp.QuoteMeta(u.Scheme+"://"+u.Host+"/"))
+}
+
+// needsCalls indicates whether any enabled target in n requires the individual calls of a report.
+func (n notifications) needsCalls() bool {
+	return n.Email.Enabled && n.Email.AttachCalls
+}
+
+// notifiers returns a notifier for every enabled target in n
+// and the names of the targets in the same order, e.g. "teams".
+// The notifications are written in the configured locale.
+func (n notifications) notifiers() (m notify.Multi, targets []string) {
+	add := func(target string, notifier notify.Notifier) {
+		m = append(m, notifier)
+		targets = append(targets, target)
+	}
+	if n.Teams.Enabled {
+		templates := n.Teams.Templates
+		allowTeamsWebhook(n.Teams.WebhookURL, n.Teams.Type)
+		add("teams", notify.NewTeams(teamsClient, n.Teams.WebhookURL, &templates, locale))
+	}
+	if n.Slack.Enabled {
+		add("slack", notify.NewSlack(n.Slack.WebhookURL, locale))
+	}
+	if n.Mattermost.Enabled {
+		add("mattermost", notify.NewMattermost(n.Mattermost.WebhookURL, locale))
+	}
+	if n.Discord.Enabled {
+		add("discord", notify.NewDiscord(n.Discord.WebhookURL, locale))
+	}
+	if n.Matrix.Enabled {
+		matrix := n.Matrix.Matrix
+		matrix.Locale = locale
+		add("matrix", &matrix)
+	}
+	if n.Ntfy.Enabled {
+		ntfy := n.Ntfy.Ntfy
+		ntfy.Locale = locale
+		add("ntfy", &ntfy)
+	}
+	if n.Gotify.Enabled {
+		gotify := n.Gotify.Gotify
+		gotify.Locale = locale
+		add("gotify", &gotify)
+	}
+	if n.Email.Enabled {
+		email := n.Email.Email
+		email.Locale = locale
+		add("email", &email)
+	}
+	if n.Webhook.Enabled {
+		webhook := n.Webhook.Webhook
+		add("webhook", &webhook)
+	}
+	return m, targets
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/lmr-hh/easybell-billing-info/report"
+)
+
+// outputFormat is the format in which the report commands write reports to standard output.
+// Scheduled runs of the serve command always use the text format.
+var outputFormat = report.FormatText
+
+// addOutputFlag adds the flag that selects the output format of reports to cmd.
+func addOutputFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", report.FormatText, "The format of the reports on standard output ("+strings.Join(report.Formats, ", ")+").")
+	checkFlag(cmd, "output", func() error {
+		if !slices.Contains(report.Formats, outputFormat) {
+			return fmt.Errorf("invalid output format: must be one of %s", strings.Join(report.Formats, ", "))
+		}
+		return nil
+	})
+}
+
+// newOutput returns a writer that writes reports to standard output in the selected format.
+func newOutput() (*report.Writer, error) {
+	return report.NewWriter(os.Stdout, outputFormat, consoleLocale())
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/lmr-hh/easybell-billing-info/easybell"
+	"github.com/lmr-hh/easybell-billing-info/forecast"
+	"github.com/lmr-hh/easybell-billing-info/i18n"
+	"github.com/lmr-hh/easybell-billing-info/notify"
+	"github.com/lmr-hh/easybell-billing-info/report"
+)
+
+var (
+	previewFixture string
+	previewFile    string
+)
+
+func init() {
+	previewCommand.Flags().StringVar(&previewFixture, "fixture", "", "A file with reports in the JSON output format that are previewed instead of the sample reports.")
+	previewCommand.Flags().StringVar(&previewFile, "out", "card-preview.html", "The HTML file to which the preview is written. Use - for standard output.")
+	rootCommand.AddCommand(previewCommand)
+}
+
+// previewCommand renders the Teams cards of sample reports into an HTML page.
+var previewCommand = &cobra.Command{
+	Use:   "preview",
+	Short: "Render the Teams cards of sample reports into a local HTML page.",
+	Long: "Render the Adaptive Cards of sample reports or of the reports in a fixture file into an\n" +
+		"HTML page that approximates the look of Microsoft Teams. The cards are rendered from the\n" +
+		"configured card templates in the configured language. No reports are fetched or sent.",
+	Args: cobra.NoArgs,
+	// The preview must not log in to easyBell.
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runPreview(cmd)
+	},
+}
+
+// runPreview writes the preview of the cards to previewFile.
+func runPreview(cmd *cobra.Command) error {
+	// The configuration does not need to be complete, e.g. credentials and webhook URLs are not required.
+	// Only the settings that affect the cards are checked.
+	configErr := loadConfig(cmd)
+	if location == nil {
+		return configErr
+	}
+	if localeName != "" {
+		if _, err := i18n.Lookup(localeName); err != nil {
+			return err
+		}
+	}
+	templates := notify.CardTemplates{Dir: cardTemplates}
+	if err := templates.Validate(); err != nil {
+		return fmt.Errorf("invalid card templates: %w", err)
+	}
+
+	reports := sampleReports(time.Now().In(location))
+	if previewFixture != "" {
+		var err error
+		if reports, err = readFixture(previewFixture); err != nil {
+			return err
+		}
+	}
+	var page bytes.Buffer
+	if err := templates.Preview(&page, reports, locale); err != nil {
+		return err
+	}
+	if previewFile == "-" {
+		_, err := os.Stdout.Write(page.Bytes())
+		return err
+	}
+	if err := os.WriteFile(previewFile, page.Bytes(), 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote the preview of %d card(s) to %s.\n", len(reports), previewFile)
+	return nil
+}
+
+// readFixture reads the reports from a file in the JSON output format.
+// The file contains one document per line or a sequence of documents.
+func readFixture(path string) ([]*report.Report, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	var reports []*report.Report
+	decoder := json.NewDecoder(f)
+	for {
+		var d report.Document
+		if err = decoder.Decode(&d); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		r, err := d.Report()
+		if err != nil {
+			return nil, fmt.Errorf("%s: report %d: %w", path, len(reports)+1, err)
+		}
+		reports = append(reports, r)
+	}
+	if len(reports) == 0 {
+		return nil, fmt.Errorf("%s does not contain any reports", path)
+	}
+	return reports, nil
+}
+
+// sampleReports returns reports of every kind for two sample accounts in the billing periods at now.
+// The usage is chosen relative to the configured quotas so that the cards show every status.
+// If no quotas are configured, sample quotas are used.
+func sampleReports(now time.Time) []*report.Report {
+	tariff := globalTariff()
+	if tariff.NationalQuota == 0 {
+		tariff.NationalQuota = 1000 * time.Minute
+	}
+	if tariff.MobileQuota == 0 {
+		tariff.MobileQuota = 100 * time.Minute
+	}
+	// usage returns the usage at the specified percentages of the quotas and the international usage in minutes.
+	usage := func(national, mobile, other float64) easybell.Usage {
+		return easybell.Usage{
+			National: time.Duration(float64(tariff.NationalQuota) * national / 100).Round(time.Second),
+			Mobile:   time.Duration(float64(tariff.MobileQuota) * mobile / 100).Round(time.Second),
+			Other:    time.Duration(other * float64(time.Minute)).Round(time.Second),
+		}
+	}
+	scale := func(u easybell.Usage, f float64) easybell.Usage {
+		return easybell.Usage{
+			National: time.Duration(float64(u.National) * f).Round(time.Second),
+			Mobile:   time.Duration(float64(u.Mobile) * f).Round(time.Second),
+			Other:    time.Duration(float64(u.Other) * f).Round(time.Second),
+		}
+	}
+
+	start, end := cycle.Previous(now)
+	previous := &report.Report{Kind: report.KindPrevious, Account: "Berlin", Start: start, End: end, Usage: usage(72, 104, 14), Tariff: tariff}
+
+	start, end = cycle.Period(now)
+	elapsed := float64(now.Sub(start)) / float64(end.Sub(start))
+	// current returns a report of account whose usage is projected to reach estimate at the end of the period.
+	current := func(account string, estimate easybell.Usage) *report.Report {
+		r := &report.Report{Kind: report.KindCurrent, Account: account, Start: start, End: end, Usage: scale(estimate, elapsed), Tariff: tariff}
+		r.Forecast = &report.Forecast{
+			Model:    forecast.ModelLinear,
+			Window:   35 * 24 * time.Hour,
+			Estimate: estimate,
+			Low:      scale(estimate, 0.85),
+			High:     scale(estimate, 1.15),
+			Level:    0.8,
+		}
+		if estimate.Mobile > tariff.MobileQuota {
+			t := start.Add(time.Duration(float64(end.Sub(start)) * float64(tariff.MobileQuota) / float64(estimate.Mobile)))
+			r.Forecast.MobileExhausted = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+		}
+		return r
+	}
+	berlin := current("Berlin", usage(93, 120, 0))
+	hamburg := current("Hamburg", usage(41, 55, 6))
+	return []*report.Report{previous, berlin, newSummary(start, end, []*report.Report{berlin, hamburg})}
+}
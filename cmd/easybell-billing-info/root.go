@@ -1,36 +1,66 @@
 package main
 
 import (
-	"errors"
-	"fmt"
-	"os"
 	"time"
 
 	goteamsnotify "github.com/atc0005/go-teams-notify/v2"
 	"github.com/spf13/cobra"
 
 	"github.com/lmr-hh/easybell-billing-info/easybell"
+	"github.com/lmr-hh/easybell-billing-info/i18n"
+	"github.com/lmr-hh/easybell-billing-info/notify"
 )
 
 var (
-	client          *easybell.Client
-	sendWebhook     bool
-	teamsWebhookURL string
-	teamsClient     *goteamsnotify.TeamsClient
+	configFile       string
+	summary          bool
+	sendWebhook      bool
+	teamsWebhookURL  string
+	teamsWebhookType string
+	cardTemplates    string
+	teamsClient      *goteamsnotify.TeamsClient
+	// teamsPatternsAdded indicates whether URL patterns have been added to teamsClient.
+	teamsPatternsAdded bool
 
 	NationalQuota       time.Duration
 	MobileQuota         time.Duration
 	NationalMinutePrice float64
 	MobileMinutePrice   float64
+
+	billingDay   int
+	billingDates []string
+	timezone     string
+	location     *time.Location
+
+	// localeName is the language of the console output and all notifications.
+	// If it is not set, the console output is in English and notifications are in German.
+	localeName string
+	locale     *i18n.Locale
 )
 
 func init() {
+	rootCommand.PersistentFlags().StringVarP(&configFile, "config", "c", "", "Path to a configuration file.")
 	rootCommand.PersistentFlags().DurationVarP(&NationalQuota, "national-minutes", "n", 0, "The included monthly quota for national calls.")
 	rootCommand.PersistentFlags().DurationVarP(&MobileQuota, "mobile-minutes", "m", 0, "The included monthly quota of mobile calls.")
 	rootCommand.PersistentFlags().Float64Var(&NationalMinutePrice, "national-price", 0.0083, "The price per minute for national phone minutes over the quota.")
 	rootCommand.PersistentFlags().Float64Var(&MobileMinutePrice, "mobile-price", 0.0824, "The price per minute for mobile phone minutes over the quota.")
+	rootCommand.PersistentFlags().IntVar(&billingDay, "billing-day", 1, "The day of the month on which a new billing period starts.")
+	rootCommand.PersistentFlags().StringSliceVar(&billingDates, "billing-dates", nil, "Explicit start dates of billing periods (YYYY-MM-DD).")
+	rootCommand.PersistentFlags().StringVar(&timezone, "timezone", easybell.Location.String(), "The time zone in which billing periods are computed.")
+	rootCommand.PersistentFlags().BoolVar(&summary, "summary", false, "Send a combined summary of all accounts.")
 	rootCommand.PersistentFlags().BoolVar(&sendWebhook, "teams-webhook", true, "Send the report to a teams webhook.")
 	rootCommand.PersistentFlags().StringVarP(&teamsWebhookURL, "webhook-url", "u", "", "Teams Webhook URL to send notifications to.")
+	rootCommand.PersistentFlags().StringVar(&cardTemplates, "card-templates", "", "A directory with Adaptive Card templates that override the built-in ones.")
+	rootCommand.PersistentFlags().StringVar(&localeName, "locale", "", "The language of the console output and all notifications (de or en).")
+	rootCommand.PersistentFlags().StringVar(&teamsWebhookType, "webhook-type", notify.TeamsAuto, "The kind of the Teams webhook (auto, connector or workflow).")
+}
+
+// consoleLocale returns the language of the console output.
+func consoleLocale() *i18n.Locale {
+	if locale == nil {
+		return i18n.English
+	}
+	return locale
 }
 
 var rootCommand = &cobra.Command{
@@ -41,36 +71,9 @@ var rootCommand = &cobra.Command{
 	SilenceUsage: true,
 	Args:         cobra.NoArgs,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-		client = easybell.NewClient()
-		username := os.Getenv("EASYBELL_USERNAME")
-		if username == "" {
-			return errors.New("no username specified")
-		}
-		password := os.Getenv("EASYBELL_PASSWORD")
-		if password == "" {
-			return errors.New("no password specified")
-		}
-		if NationalQuota == 0 {
-			var err error
-			if NationalQuota, err = time.ParseDuration(os.Getenv("EASYBELL_NATIONAL_MINUTES")); err != nil {
-				return fmt.Errorf("invalid national minutes: %w", err)
-			}
-		}
-		if MobileQuota == 0 {
-			var err error
-			if MobileQuota, err = time.ParseDuration(os.Getenv("EASYBELL_MOBILE_MINUTES")); err != nil {
-				return fmt.Errorf("invalid mobile minutes: %w", err)
-			}
-		}
-		if teamsWebhookURL == "" {
-			teamsWebhookURL = os.Getenv("EASYBELL_TEAMS_WEBHOOK")
-		}
-		if sendWebhook {
-			teamsClient = goteamsnotify.NewTeamsClient()
-			if err := teamsClient.ValidateWebhook(teamsWebhookURL); err != nil {
-				return err
-			}
+		if err := loadConfig(cmd); err != nil {
+			return err
 		}
-		return client.Login(username, password)
+		return checkFlags(cmd)
 	},
 }
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// secrets contains the values of all secrets that have been loaded.
+// They are removed from error messages by redact.
+var secrets []string
+
+// minSecretLength is the minimum length of secrets that are redacted.
+// Replacing shorter values would garble error messages without protecting anything.
+const minSecretLength = 4
+
+// registerSecret records s as a secret and returns it.
+func registerSecret(s string) string {
+	if len(s) >= minSecretLength {
+		secrets = append(secrets, s)
+	}
+	return s
+}
+
+// lookupSecretEnv returns the value of the environment variable name.
+// If name is not set, the secret is read from the file referenced by the variable name_FILE.
+// It is an error if both variables are set.
+func lookupSecretEnv(name string) (string, error) {
+	value := os.Getenv(name)
+	file := os.Getenv(name + "_FILE")
+	switch {
+	case value != "" && file != "":
+		return "", fmt.Errorf("only one of %s and %s_FILE may be set", name, name)
+	case file != "":
+		secret, err := readSecretFile(file)
+		if err != nil {
+			return "", fmt.Errorf("%s_FILE: %w", name, err)
+		}
+		return secret, nil
+	default:
+		return registerSecret(value), nil
+	}
+}
+
+// readSecretFile reads a secret from the file at path.
+// Trailing line breaks are removed.
+func readSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return registerSecret(strings.TrimRight(string(data), "\r\n")), nil
+}
+
+// runSecretCommand runs the command described by args and returns its standard output as a secret.
+// Trailing line breaks are removed.
+// The output of the command is never included in the returned error.
+func runSecretCommand(args []string) (string, error) {
+	if len(args) == 0 {
+		return "", errors.New("empty command")
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stderr = os.Stderr
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("command %s failed: %w", args[0], err)
+	}
+	secret := strings.TrimRight(stdout.String(), "\r\n")
+	if secret == "" {
+		return "", fmt.Errorf("command %s did not output a secret", args[0])
+	}
+	return registerSecret(secret), nil
+}
+
+// redactedError wraps an error whose message contained secrets.
+type redactedError struct {
+	err error
+	msg string
+}
+
+func (e *redactedError) Error() string {
+	return e.msg
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+// redact returns err with all registered secrets removed from its message.
+func redact(err error) error {
+	if err == nil {
+		return nil
+	}
+	msg := err.Error()
+	for _, s := range secrets {
+		msg = strings.ReplaceAll(msg, s, "[REDACTED]")
+	}
+	if msg == err.Error() {
+		return err
+	}
+	return &redactedError{err, msg}
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/lmr-hh/easybell-billing-info/cron"
+	"github.com/lmr-hh/easybell-billing-info/easybell"
+	"github.com/lmr-hh/easybell-billing-info/metrics"
+)
+
+// schedules contains the schedules of the configuration file.
+var schedules []scheduleConfig
+
+func init() {
+	addForecastFlags(serveCommand)
+	addAlertFlags(serveCommand)
+	addMetricsFlags(serveCommand)
+	serveCommand.Flags().StringVar(&listenAddress, "listen", "", "The address on which metrics are served via HTTP, e.g. :9090.")
+	serveCommand.Flags().StringSliceVar(&apiTokens, "api-tokens", nil, "Bearer tokens that grant access to the REST API. The API is disabled if no tokens are set.")
+	serveCommand.Flags().DurationVar(&cache.ttl, "cache-ttl", 5*time.Minute, "The time for which the usage is cached before it is fetched from easyBell again.")
+	checkFlag(serveCommand, "cache-ttl", func() error {
+		if cache.ttl <= 0 {
+			return errors.New("cache TTL must be positive")
+		}
+		return nil
+	})
+	checkFlag(serveCommand, "api-tokens", validateAPITokens)
+	rootCommand.AddCommand(serveCommand)
+}
+
+// scheduleJobs maps the commands that can be scheduled to their implementations.
+var scheduleJobs = map[string]func(ctx context.Context) error{
+	"last-month":    runLastMonth,
+	"current-month": runCurrentMonth,
+	"alert":         runAlert,
+	"metrics":       runMetrics,
+}
+
+// A job is a command that runs on a schedule.
+type job struct {
+	command  string
+	schedule *cron.Schedule
+	location *time.Location
+	run      func(ctx context.Context) error
+	next     time.Time
+	// state is the result of the last run, which is exposed as metrics.
+	// It is protected by jobsMu.
+	state metrics.Job
+}
+
+var (
+	// scheduledJobs contains the jobs of the serve command.
+	scheduledJobs []*job
+	jobsMu        sync.Mutex
+)
+
+// jobMetrics returns the metrics of the results of the scheduled jobs.
+func jobMetrics() []metrics.Metric {
+	jobsMu.Lock()
+	defer jobsMu.Unlock()
+	states := make([]metrics.Job, len(scheduledJobs))
+	for i, j := range scheduledJobs {
+		states[i] = j.state
+	}
+	return metrics.JobMetrics(states)
+}
+
+// newJobs returns a job for every configured schedule.
+func newJobs() ([]*job, error) {
+	jobs := make([]*job, len(schedules))
+	for i, s := range schedules {
+		j := &job{command: s.Command, location: location, run: scheduleJobs[s.Command]}
+		j.state = metrics.Job{Command: s.Command, Schedule: s.Cron}
+		if j.run == nil {
+			return nil, fmt.Errorf("schedules[%d].command: unknown command %q", i, s.Command)
+		}
+		var err error
+		if j.schedule, err = cron.Parse(s.Cron); err != nil {
+			return nil, fmt.Errorf("schedules[%d].cron: %w", i, err)
+		}
+		if s.Timezone != "" {
+			if j.location, err = time.LoadLocation(s.Timezone); err != nil {
+				return nil, fmt.Errorf("schedules[%d].timezone: %w", i, err)
+			}
+		}
+		jobs[i] = j
+	}
+	return jobs, nil
+}
+
+// advance computes the next time after t at which j is due and logs it.
+func (j *job) advance(t time.Time) {
+	j.next = j.schedule.Next(t.In(j.location))
+	if j.next.IsZero() {
+		log.Printf("%s: never due", j.command)
+	} else {
+		log.Printf("%s: next run at %s", j.command, j.next.Format(time.RFC3339))
+	}
+}
+
+var serveCommand = &cobra.Command{
+	Use:   "serve",
+	Short: "Run the configured schedules and serve metrics until the process is terminated.",
+	Long: "Run the commands of the schedules in the configuration file on their cron expressions\n" +
+		"and serve the usage metrics via HTTP if a listen address is set.\n" +
+		"The accounts stay logged in between runs. Failed runs are logged to standard error and do not\n" +
+		"stop the process. The result of the last run of every schedule is exposed in the metrics.\n" +
+		"On SIGINT or SIGTERM a running command is completed before the process exits.",
+	Args: cobra.NoArgs,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		registerMetricsSecrets()
+		return loadModel()
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		jobs, err := newJobs()
+		if err != nil {
+			return err
+		}
+		if len(jobs) == 0 && listenAddress == "" {
+			return errors.New("no schedules or listen address configured")
+		}
+		scheduledJobs = jobs
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		go func() {
+			// A second signal terminates the process immediately.
+			<-ctx.Done()
+			stop()
+		}()
+
+		keepSessions = true
+		// Logging in at startup reveals invalid credentials before the first job is due.
+		_, errs := forEachAccount(func(a *account, client *easybell.Client) (struct{}, error) {
+			return struct{}{}, nil
+		})
+		if err = errors.Join(errs...); err != nil {
+			return err
+		}
+		defer logout()
+
+		if listenAddress != "" {
+			listener, err := net.Listen("tcp", listenAddress)
+			if err != nil {
+				return err
+			}
+			server := &http.Server{Handler: newHandler(), ReadHeaderTimeout: 10 * time.Second}
+			go func() {
+				if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+					log.Printf("server failed: %v", err)
+					stop()
+				}
+			}()
+			log.Printf("listening on %s", listener.Addr())
+			defer func() {
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				defer cancel()
+				_ = server.Shutdown(shutdownCtx)
+			}()
+		}
+
+		runJobs(ctx, jobs)
+		log.Print("shutting down")
+		return nil
+	},
+}
+
+// runJobs runs jobs on their schedules until ctx is done.
+func runJobs(ctx context.Context, jobs []*job) {
+	now := time.Now()
+	for _, j := range jobs {
+		j.advance(now)
+	}
+	for {
+		var next *job
+		for _, j := range jobs {
+			if !j.next.IsZero() && (next == nil || j.next.Before(next.next)) {
+				next = j
+			}
+		}
+		if next == nil {
+			<-ctx.Done()
+			return
+		}
+		timer := time.NewTimer(time.Until(next.next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		for _, j := range jobs {
+			if ctx.Err() != nil {
+				break
+			}
+			if j.next.IsZero() || j.next.After(time.Now()) {
+				continue
+			}
+			// Running jobs are not canceled on shutdown so that reports are not sent partially.
+			// Failures are logged and exposed as metrics because the notification targets receive reports, not errors.
+			start := time.Now()
+			err := j.run(context.WithoutCancel(ctx))
+			if err != nil {
+				for _, err := range flattenErrors(err) {
+					log.Printf("%s failed: %v", j.command, redact(err))
+				}
+			}
+			j.record(start, err)
+			j.advance(time.Now())
+		}
+	}
+}
+
+// record records the result err of the run of j that started at start.
+func (j *job) record(start time.Time, err error) {
+	jobsMu.Lock()
+	defer jobsMu.Unlock()
+	j.state.LastRun = start
+	j.state.Duration = time.Since(start)
+	j.state.Success = err == nil
+	if err == nil {
+		j.state.LastSuccess = start
+	}
+}
+
+// logout ends the sessions of all accounts.
+func logout() {
+	for _, a := range accounts {
+		if a.client != nil {
+			_ = a.client.Logout()
+			a.client = nil
+		}
+	}
+}
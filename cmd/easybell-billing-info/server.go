@@ -0,0 +1,32 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/lmr-hh/easybell-billing-info/metrics"
+)
+
+var (
+	listenAddress string
+	cache         = &usageCache{}
+)
+
+// newHandler returns the HTTP handler of the serve command.
+func newHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics", handleMetrics)
+	registerAPI(mux)
+	return mux
+}
+
+// handleMetrics serves the usage metrics and the metrics of the scheduled jobs in the Prometheus text exposition format.
+func handleMetrics(w http.ResponseWriter, r *http.Request) {
+	var b bytes.Buffer
+	if err := metrics.WritePrometheus(&b, append(cache.get().Metrics(), jobMetrics()...)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", metrics.PrometheusContentType)
+	_, _ = w.Write(b.Bytes())
+}
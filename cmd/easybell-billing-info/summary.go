@@ -0,0 +1,17 @@
+package main
+
+import (
+	"time"
+
+	"github.com/lmr-hh/easybell-billing-info/report"
+)
+
+// newSummary returns a combined report of reports in the period between start and end.
+func newSummary(start, end time.Time, reports []*report.Report) *report.Report {
+	return &report.Report{
+		Kind:     report.KindSummary,
+		Start:    start,
+		End:      end,
+		Accounts: reports,
+	}
+}
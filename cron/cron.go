@@ -0,0 +1,225 @@
+// Package cron parses cron expressions and computes the times at which they are due.
+//
+// An expression consists of the five standard fields minute, hour, day of month, month and day of week.
+// Every field may contain lists, ranges and steps, e.g. "1-5", "*/15" or "8,12,16".
+// Months and days of the week may also be given by their English abbreviations, e.g. "jan" or "mon-fri".
+// If both the day of month and the day of week are restricted, a day matches if it matches either field.
+//
+// Changes of daylight saving time are handled like by Vixie cron:
+// Times that are skipped when the clocks are moved forward run at the first valid time after the change,
+// times that occur twice when the clocks are moved back only run once.
+// Schedules whose minute or hour field starts with an asterisk are not adjusted,
+// they skip the missing times and run at both occurrences of repeated times.
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A Schedule is a parsed cron expression.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar indicate that the day fields start with an asterisk.
+	domStar, dowStar bool
+	// wildcard indicates that the minute or the hour field starts with an asterisk.
+	wildcard bool
+}
+
+// field describes the valid values of a field of an expression.
+type field struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+var (
+	minuteField = field{"minute", 0, 59, nil}
+	hourField   = field{"hour", 0, 23, nil}
+	domField    = field{"day of month", 1, 31, nil}
+	monthField  = field{"month", 1, 12, []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}}
+	// Sunday can be given as 0 or 7.
+	dowField = field{"day of week", 0, 7, []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}}
+)
+
+// Parse parses a cron expression.
+func Parse(expr string) (*Schedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron expression %q: must consist of 5 fields", expr)
+	}
+	s := &Schedule{
+		domStar:  strings.HasPrefix(fields[2], "*"),
+		dowStar:  strings.HasPrefix(fields[4], "*"),
+		wildcard: strings.HasPrefix(fields[0], "*") || strings.HasPrefix(fields[1], "*"),
+	}
+	var err error
+	for i, f := range []struct {
+		bits  *uint64
+		field field
+	}{
+		{&s.minute, minuteField},
+		{&s.hour, hourField},
+		{&s.dom, domField},
+		{&s.month, monthField},
+		{&s.dow, dowField},
+	} {
+		if *f.bits, err = f.field.parse(fields[i]); err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %w", expr, err)
+		}
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	return s, nil
+}
+
+// parse returns the set of values of f described by expr as a bit set.
+func (f field) parse(expr string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(expr, ",") {
+		rng, stepExpr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepExpr); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q in %s field", stepExpr, f.name)
+			}
+		}
+		low, high := f.min, f.max
+		if rng != "*" {
+			lowExpr, highExpr, isRange := strings.Cut(rng, "-")
+			var err error
+			if low, err = f.value(lowExpr); err != nil {
+				return 0, err
+			}
+			high = low
+			if isRange {
+				if high, err = f.value(highExpr); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				high = f.max
+			}
+			if low > high {
+				return 0, fmt.Errorf("invalid range %q in %s field", rng, f.name)
+			}
+		}
+		for v := low; v <= high; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+// value parses a single value of f given as a number or a name.
+func (f field) value(expr string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(expr, name) {
+			return f.min + i, nil
+		}
+	}
+	v, err := strconv.Atoi(expr)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("invalid value %q in %s field, must be between %d and %d", expr, f.name, f.min, f.max)
+	}
+	return v, nil
+}
+
+// maxOffsetChange is the largest change of the UTC offset of a location at a single transition.
+const maxOffsetChange = 3 * time.Hour
+
+// Next returns the first time after t at which s is due.
+// The fields of s are interpreted in the location of t.
+// If s is never due within the next five years, e.g. for February 30, the zero time is returned.
+func (s *Schedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	// Wall clock times are searched in UTC, where every day has the same hours, and then resolved in loc.
+	// Around a change of daylight saving time a later wall clock time may occur earlier,
+	// so the search starts before the wall clock time of t and keeps the earliest time after t.
+	wall := wallClock(t)
+	limit := wall.AddDate(5, 0, 0)
+	var next time.Time
+	for c := s.nextWallClock(wall.Add(-maxOffsetChange), limit); !c.IsZero(); c = s.nextWallClock(c, limit) {
+		if !next.IsZero() && c.After(wallClock(next).Add(maxOffsetChange)) {
+			break
+		}
+		for _, u := range s.resolve(c, loc) {
+			if u.After(t) && (next.IsZero() || u.Before(next)) {
+				next = u
+			}
+		}
+	}
+	return next
+}
+
+// nextWallClock returns the first wall clock time after c in UTC that matches s.
+// If there is none before limit, the zero time is returned.
+func (s *Schedule) nextWallClock(c, limit time.Time) time.Time {
+	c = time.Date(c.Year(), c.Month(), c.Day(), c.Hour(), c.Minute()+1, 0, 0, time.UTC)
+	for c.Before(limit) {
+		switch {
+		case s.month&(1<<uint(c.Month())) == 0:
+			c = time.Date(c.Year(), c.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !s.dayMatches(c):
+			c = time.Date(c.Year(), c.Month(), c.Day()+1, 0, 0, 0, 0, time.UTC)
+		case s.hour&(1<<uint(c.Hour())) == 0:
+			c = time.Date(c.Year(), c.Month(), c.Day(), c.Hour()+1, 0, 0, 0, time.UTC)
+		case s.minute&(1<<uint(c.Minute())) == 0:
+			c = time.Date(c.Year(), c.Month(), c.Day(), c.Hour(), c.Minute()+1, 0, 0, time.UTC)
+		default:
+			return c
+		}
+	}
+	return time.Time{}
+}
+
+// resolve returns the times in chronological order at which s runs for the wall clock time c in UTC in loc.
+func (s *Schedule) resolve(c time.Time, loc *time.Location) []time.Time {
+	// The offsets a day before and after c are those before and after a transition near c.
+	var candidates, times []time.Time
+	for _, probe := range []time.Time{c.Add(-24 * time.Hour), c.Add(24 * time.Hour)} {
+		_, offset := probe.In(loc).Zone()
+		u := c.Add(-time.Duration(offset) * time.Second).In(loc)
+		candidates = append(candidates, u)
+		if wallClock(u).Equal(c) && (len(times) == 0 || !times[0].Equal(u)) {
+			times = append(times, u)
+		}
+	}
+	if len(times) == 2 && times[1].Before(times[0]) {
+		times[0], times[1] = times[1], times[0]
+	}
+	switch {
+	case s.wildcard:
+		return times
+	case len(times) == 0:
+		// c is skipped because the clocks are moved forward, so s runs at the time of the change.
+		// The later candidate is after the change.
+		after := candidates[0]
+		if candidates[1].After(after) {
+			after = candidates[1]
+		}
+		start, _ := after.ZoneBounds()
+		return []time.Time{start}
+	default:
+		// If c occurs twice because the clocks are moved back, s only runs the first time.
+		return times[:1]
+	}
+}
+
+// wallClock returns the wall clock time of t in UTC.
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+// dayMatches indicates whether the day of t matches the day of month and day of week of s.
+func (s *Schedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
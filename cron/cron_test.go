@@ -0,0 +1,202 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+// bits returns a bit set with the specified values.
+func bits(values ...int) uint64 {
+	var b uint64
+	for _, v := range values {
+		b |= 1 << v
+	}
+	return b
+}
+
+// between returns a bit set with the values from low to high in steps of step.
+func between(low, high, step int) uint64 {
+	var b uint64
+	for v := low; v <= high; v += step {
+		b |= 1 << v
+	}
+	return b
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expr                          string
+		minute, hour, dom, month, dow uint64
+	}{
+		{"* * * * *", between(0, 59, 1), between(0, 23, 1), between(1, 31, 1), between(1, 12, 1), between(0, 7, 1)},
+		{"0 8 1 * *", bits(0), bits(8), bits(1), between(1, 12, 1), between(0, 7, 1)},
+		{"1-5 8-18 * * *", between(1, 5, 1), between(8, 18, 1), between(1, 31, 1), between(1, 12, 1), between(0, 7, 1)},
+		{"*/15 */6 */10 */3 *", bits(0, 15, 30, 45), bits(0, 6, 12, 18), bits(1, 11, 21, 31), bits(1, 4, 7, 10), between(0, 7, 1)},
+		{"10-50/20 8/4 5/10 * *", bits(10, 30, 50), bits(8, 12, 16, 20), bits(5, 15, 25), between(1, 12, 1), between(0, 7, 1)},
+		{"0,30 8,12,16 1,15 * *", bits(0, 30), bits(8, 12, 16), bits(1, 15), between(1, 12, 1), between(0, 7, 1)},
+		{"0 0 * jan-mar,DEC *", bits(0), bits(0), between(1, 31, 1), bits(1, 2, 3, 12), between(0, 7, 1)},
+		{"0 0 * * mon-fri", bits(0), bits(0), between(1, 31, 1), between(1, 12, 1), between(1, 5, 1)},
+		{"0 0 * * Sat,sun", bits(0), bits(0), between(1, 31, 1), between(1, 12, 1), bits(0, 6)},
+		{"0 0 * * 7", bits(0), bits(0), between(1, 31, 1), between(1, 12, 1), bits(0, 7)},
+		{"0 0 * * 5-7", bits(0), bits(0), between(1, 31, 1), between(1, 12, 1), bits(0, 5, 6, 7)},
+		{"  0   0  *  *  0 ", bits(0), bits(0), between(1, 31, 1), between(1, 12, 1), bits(0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, f := range []struct {
+				name      string
+				got, want uint64
+			}{
+				{"minute", s.minute, tt.minute},
+				{"hour", s.hour, tt.hour},
+				{"day of month", s.dom, tt.dom},
+				{"month", s.month, tt.month},
+				{"day of week", s.dow, tt.dow},
+			} {
+				if f.got != f.want {
+					t.Errorf("%s = %b, want %b", f.name, f.got, f.want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"1-x * * * *",
+		"* * * foo *",
+		"* * * * monday",
+		"* * * * sun-sat-mon",
+		"1,,2 * * * *",
+	} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", expr)
+		}
+	}
+}
+
+// date returns the time of a wall clock value in the format "2006-01-02 15:04" in loc.
+func date(t *testing.T, value string, loc *time.Location) time.Time {
+	t.Helper()
+	v, err := time.ParseInLocation("2006-01-02 15:04", value, loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		t    string
+		want string
+	}{
+		{"next minute", "* * * * *", "2026-01-15 10:00", "2026-01-15 10:01"},
+		{"seconds are ignored", "*/5 * * * *", "2026-01-15 10:04", "2026-01-15 10:05"},
+		{"later today", "0 8,12 * * *", "2026-01-15 10:00", "2026-01-15 12:00"},
+		{"tomorrow", "0 8 * * *", "2026-01-15 08:00", "2026-01-16 08:00"},
+		{"next month", "0 8 1 * *", "2026-01-15 10:00", "2026-02-01 08:00"},
+		{"next year", "0 0 1 jan *", "2026-01-15 10:00", "2027-01-01 00:00"},
+		{"day of week", "0 8 * * mon", "2026-01-15 10:00", "2026-01-19 08:00"},
+		{"sunday as 7", "0 8 * * 7", "2026-01-15 10:00", "2026-01-18 08:00"},
+		{"day of month or day of week matches the day of week", "0 8 13 * fri", "2026-01-15 10:00", "2026-01-16 08:00"},
+		{"day of month or day of week matches the day of month", "0 8 13 * fri", "2026-04-11 10:00", "2026-04-13 08:00"},
+		{"day of month with asterisk day of week", "0 8 13 * *", "2026-01-15 10:00", "2026-02-13 08:00"},
+		{"day of week with stepped day of month", "0 8 */1 * fri", "2026-01-15 10:00", "2026-01-16 08:00"},
+		{"leap day", "0 0 29 feb *", "2026-01-15 10:00", "2028-02-29 00:00"},
+		{"last day of long months", "0 0 31 * *", "2026-04-01 00:00", "2026-05-31 00:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			from := date(t, tt.t, time.UTC).Add(30 * time.Second)
+			if got, want := s.Next(from), date(t, tt.want, time.UTC); !got.Equal(want) {
+				t.Errorf("Next(%s) = %s, want %s", from, got, want)
+			}
+		})
+	}
+}
+
+func TestNextNever(t *testing.T) {
+	s, err := Parse("0 0 30 feb *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next := s.Next(time.Now()); !next.IsZero() {
+		t.Errorf("Next = %s, want the zero time", next)
+	}
+}
+
+// TestNextDST checks the schedules around the changes of daylight saving time in Europe/Berlin.
+// On 2026-03-29 the clocks are moved forward from 02:00 CET to 03:00 CEST,
+// on 2026-10-25 they are moved back from 03:00 CEST to 02:00 CET.
+func TestNextDST(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip(err)
+	}
+	tests := []struct {
+		name string
+		expr string
+		// t and want are given in UTC because wall clock times around the changes are ambiguous.
+		t    string
+		want string
+	}{
+		// Spring forward.
+		{"skipped time runs after the change", "30 2 * * *", "2026-03-28 11:00", "2026-03-29 01:00"},
+		{"skipped time runs once", "30 2 * * *", "2026-03-29 01:00", "2026-03-30 00:30"},
+		{"skipped times run once", "0,15,30,45 2 * * *", "2026-03-28 11:00", "2026-03-29 01:00"},
+		{"skipped time with matching time after the change", "30 2,3 * * *", "2026-03-29 00:50", "2026-03-29 01:00"},
+		{"skipped time with matching time after the change runs once", "30 2,3 * * *", "2026-03-29 01:00", "2026-03-29 01:30"},
+		{"time before the change", "30 1 * * *", "2026-03-28 11:00", "2026-03-29 00:30"},
+		{"time after the change", "30 3 * * *", "2026-03-28 11:00", "2026-03-29 01:30"},
+		{"wildcard hour skips the missing hour", "30 * * * *", "2026-03-29 00:45", "2026-03-29 01:30"},
+		{"wildcard minute skips the missing hour", "* 2,3 * * *", "2026-03-29 00:45", "2026-03-29 01:00"},
+		{"every minute across the change", "* * * * *", "2026-03-29 00:59", "2026-03-29 01:00"},
+		// Fall back.
+		{"repeated time runs at the first occurrence", "30 2 * * *", "2026-10-24 10:00", "2026-10-25 00:30"},
+		{"repeated time does not run at the second occurrence", "30 2 * * *", "2026-10-25 00:30", "2026-10-26 01:30"},
+		{"repeated time after the first occurrence", "15 2 * * *", "2026-10-25 00:30", "2026-10-26 01:15"},
+		{"time after the change", "30 3 * * *", "2026-10-24 10:00", "2026-10-25 02:30"},
+		{"wildcard hour runs at both occurrences", "30 * * * *", "2026-10-25 00:30", "2026-10-25 01:30"},
+		{"wildcard hour after the second occurrence", "30 * * * *", "2026-10-25 01:30", "2026-10-25 02:30"},
+		{"wildcard minute at the start of the second occurrence", "*/30 * * * *", "2026-10-25 00:45", "2026-10-25 01:00"},
+		{"every minute across the change", "* * * * *", "2026-10-25 00:59", "2026-10-25 01:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			from := date(t, tt.t, time.UTC).In(berlin)
+			got, want := s.Next(from), date(t, tt.want, time.UTC)
+			if !got.Equal(want) {
+				t.Errorf("Next(%s) = %s, want %s", from, got, want.In(berlin))
+			}
+			if got.Location() != berlin {
+				t.Errorf("Location = %s, want %s", got.Location(), berlin)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+// Package dashboard contains the web dashboard of the serve command.
+//
+// The dashboard is a single page whose static assets are embedded in [Assets].
+// It renders the [Data] that is served as JSON by the REST API.
+// Gauges, forecasts and monthly usage are rendered from the data model of the card templates
+// so that the dashboard and the cards in chat messages show the same values.
+package dashboard
+
+import (
+	"embed"
+	"io/fs"
+	"slices"
+	"time"
+
+	"github.com/lmr-hh/easybell-billing-info/easybell"
+	"github.com/lmr-hh/easybell-billing-info/i18n"
+	"github.com/lmr-hh/easybell-billing-info/notify"
+	"github.com/lmr-hh/easybell-billing-info/report"
+)
+
+//go:embed static
+var static embed.FS
+
+// Assets contains the HTML, CSS and JavaScript files of the dashboard.
+var Assets = func() fs.FS {
+	assets, err := fs.Sub(static, "static")
+	if err != nil {
+		panic(err)
+	}
+	return assets
+}()
+
+// Data is the data model of the dashboard.
+type Data struct {
+	// Time is the time at which the usage of the current billing period was fetched.
+	Time time.Time `json:"time"`
+	// Labels contains the translated texts of the dashboard, keyed by the names used in the assets.
+	Labels   map[string]string `json:"labels"`
+	Accounts []Account         `json:"accounts"`
+}
+
+// An Account is the dashboard of a single account.
+type Account struct {
+	// Name is the name of the account.
+	// If only a single account is configured, the name is empty.
+	Name string `json:"name"`
+	// Card is the card data model of the report of the current billing period.
+	Card any `json:"card"`
+	// Report is the report of the current billing period.
+	Report report.Document `json:"report"`
+	// Days contains the usage per day of the current billing period.
+	Days []Day `json:"days"`
+	// Periods contains the completed billing periods, the most recent last.
+	// If the usage of the periods could not be fetched, Periods is nil.
+	Periods []Period `json:"periods"`
+	// Calls contains the calls of the current billing period, the most recent first.
+	Calls []report.DocumentCall `json:"calls"`
+}
+
+// A Day is the usage of a single day.
+type Day struct {
+	Date  string               `json:"date"`
+	Usage report.DocumentUsage `json:"usage"`
+}
+
+// A Period is a completed billing period.
+type Period struct {
+	Name string `json:"name"`
+	// Card is the card data model of the report of the period.
+	Card   any             `json:"card"`
+	Report report.Document `json:"report"`
+	Status report.Status   `json:"status"`
+}
+
+// labels maps the names of the labels used in the assets to message keys.
+var labels = map[string]string{
+	"title":    "dashboard.title",
+	"daily":    "dashboard.daily",
+	"periods":  "dashboard.periods",
+	"calls":    "dashboard.calls",
+	"search":   "dashboard.search",
+	"time":     "dashboard.time",
+	"number":   "dashboard.number",
+	"partner":  "dashboard.partner",
+	"duration": "dashboard.duration",
+	"kind":     "dashboard.kind",
+	"updated":  "dashboard.updated",
+	"period":   "dashboard.period",
+	"national": "national",
+	"mobile":   "mobile",
+	"other":    "other",
+	"cost":     "cost",
+}
+
+// Labels returns the texts of the dashboard in the language of l.
+func Labels(l *i18n.Locale) map[string]string {
+	m := make(map[string]string, len(labels))
+	for name, key := range labels {
+		m[name] = l.T(key)
+	}
+	return m
+}
+
+// NewAccount returns the dashboard of the account of the report r of the current billing period in the language of l.
+// The report must contain the calls of the period.
+// The reports in periods are the completed billing periods in chronological order.
+func NewAccount(r *report.Report, periods []*report.Report, l *i18n.Locale) (Account, error) {
+	card, err := notify.CardData(r, l)
+	if err != nil {
+		return Account{}, err
+	}
+	a := Account{Name: r.Account, Card: card, Report: r.Document(), Days: days(r), Calls: []report.DocumentCall{}}
+	for _, p := range periods {
+		card, err := notify.CardData(p, l)
+		if err != nil {
+			return a, err
+		}
+		a.Periods = append(a.Periods, Period{Name: p.PeriodName(l), Card: card, Report: p.Document(), Status: p.Status()})
+	}
+	for i := range r.Calls {
+		a.Calls = append(a.Calls, report.NewDocumentCall(r.Account, &r.Calls[i]))
+	}
+	slices.SortStableFunc(a.Calls, func(a, b report.DocumentCall) int { return b.Time.Compare(a.Time) })
+	return a, nil
+}
+
+// days returns the usage per day of all days of the billing period of r.
+// Days are determined in the time zone of the period.
+func days(r *report.Report) []Day {
+	var days []Day
+	usage := make(map[string]*easybell.Usage)
+	for t := r.Start; t.Before(r.End); t = t.AddDate(0, 0, 1) {
+		date := t.Format(time.DateOnly)
+		days = append(days, Day{Date: date})
+		usage[date] = &easybell.Usage{}
+	}
+	for i := range r.Calls {
+		if u := usage[r.Calls[i].Time.In(r.Start.Location()).Format(time.DateOnly)]; u != nil {
+			u.Add(&r.Calls[i])
+		}
+	}
+	for i := range days {
+		days[i].Usage = report.NewDocumentUsage(*usage[days[i].Date])
+	}
+	return days
+}
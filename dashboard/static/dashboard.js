@@ -0,0 +1,202 @@
+// The dashboard renders the data of the api/v1/dashboard endpoint.
+// Gauges, the forecast and the monthly usage are rendered from the card data model,
+// so they show the same values and colors as the cards in chat messages.
+"use strict";
+
+const tokenKey = "easybell-billing-info.token";
+const maxCalls = 500;
+const kinds = ["national", "mobile", "other"];
+
+let data;
+let account;
+
+// el creates an element with the specified attributes and children.
+// Strings are added as text nodes so that values from the call log are never interpreted as HTML.
+function el(tag, attrs, ...children) {
+  const e = tag.startsWith("svg:")
+    ? document.createElementNS("http://www.w3.org/2000/svg", tag.slice(4))
+    : document.createElement(tag);
+  for (const [name, value] of Object.entries(attrs || {})) {
+    e.setAttribute(name, value);
+  }
+  e.append(...children.filter((c) => c !== undefined && c !== null));
+  return e;
+}
+
+function byId(id) {
+  return document.getElementById(id);
+}
+
+function colorClass(color) {
+  return color && color !== "default" ? "color-" + color : "";
+}
+
+async function load() {
+  const token = localStorage.getItem(tokenKey);
+  if (!token) {
+    showLogin("");
+    return;
+  }
+  let resp;
+  try {
+    resp = await fetch("api/v1/dashboard", { headers: { Authorization: "Bearer " + token } });
+  } catch (e) {
+    showError(e.message);
+    return;
+  }
+  const body = await resp.json();
+  if (resp.status === 401) {
+    localStorage.removeItem(tokenKey);
+    showLogin(body.error);
+    return;
+  }
+  if (!resp.ok) {
+    showError(body.error);
+    return;
+  }
+  data = body;
+  render();
+}
+
+function showLogin(message) {
+  byId("dashboard").hidden = true;
+  byId("login").hidden = false;
+  byId("login-error").textContent = message;
+}
+
+function showError(message) {
+  byId("error").hidden = false;
+  byId("error").textContent = message;
+}
+
+function render() {
+  const labels = data.labels;
+  document.title = labels.title;
+  byId("title").textContent = labels.title;
+  byId("updated").textContent = labels.updated + ": " + new Date(data.time).toLocaleString();
+  byId("error").hidden = true;
+  byId("login").hidden = true;
+  byId("dashboard").hidden = false;
+
+  const select = byId("account");
+  if (data.accounts.length > 1 && select.options.length === 0) {
+    for (const a of data.accounts) {
+      select.append(el("option", { value: a.name }, a.name));
+    }
+    select.hidden = false;
+  }
+  account = data.accounts.find((a) => a.name === select.value) || data.accounts[0];
+
+  renderCard(account.card);
+  renderDaily(account.days);
+  renderPeriods(account.periods);
+  renderCalls();
+}
+
+function gauge(g) {
+  return el("div", { class: "gauge" },
+    el("div", { class: "label" }, g.label),
+    el("div", { class: "value " + colorClass(g.color) }, g.value),
+    g.range ? el("div", { class: "range" }, g.range) : null);
+}
+
+function renderCard(card) {
+  byId("card-title").textContent = card.title;
+  byId("usage").replaceChildren(...kinds.map((k) => gauge(card.usage[k])));
+  byId("cost").textContent = card.cost.label + ": " + card.cost.value;
+
+  const f = card.forecast;
+  byId("forecast").hidden = !f;
+  if (f) {
+    byId("forecast-title").textContent = f.title;
+    byId("forecast-gauges").replaceChildren(...kinds.map((k) => gauge(f[k])));
+    byId("forecast-exhaustion").textContent = f.exhaustion;
+    byId("forecast-text").textContent = f.text + " " + f.confidence;
+  }
+}
+
+// renderDaily draws the usage per day as stacked bars in minutes.
+function renderDaily(days) {
+  const labels = data.labels;
+  byId("daily-title").textContent = labels.daily;
+  const width = 1000;
+  const height = 200;
+  const bottom = 20;
+  const minutes = (u, k) => u[k + "_seconds"] / 60;
+  const total = (u) => kinds.reduce((sum, k) => sum + minutes(u, k), 0);
+  const max = Math.max(1, ...days.map((d) => total(d.usage)));
+  const step = width / days.length;
+  const svg = el("svg:svg", { viewBox: `0 0 ${width} ${height}`, preserveAspectRatio: "none" });
+  days.forEach((d, i) => {
+    let y = height - bottom;
+    for (const k of kinds) {
+      const h = (minutes(d.usage, k) / max) * (height - bottom - 10);
+      y -= h;
+      svg.append(el("svg:rect", { x: i * step + 1, y: y, width: step - 2, height: h, class: "bar-" + k },
+        el("svg:title", {}, `${d.date} · ${labels[k]}: ${Math.ceil(minutes(d.usage, k))} min`)));
+    }
+    if (i % 5 === 0) {
+      svg.append(el("svg:text", { x: i * step + step / 2, y: height - 5, "text-anchor": "middle" }, d.date.slice(8)));
+    }
+  });
+  const legend = el("div", { class: "legend subtle" },
+    ...kinds.map((k) => el("span", { class: k }, labels[k])));
+  byId("daily").replaceChildren(svg, legend);
+}
+
+function renderPeriods(periods) {
+  const labels = data.labels;
+  byId("periods").hidden = !periods;
+  if (!periods) {
+    return;
+  }
+  byId("periods-title").textContent = labels.periods;
+  byId("periods-head").replaceChildren(
+    ...[labels.period, labels.national, labels.mobile, labels.other, labels.cost].map((l) => el("th", {}, l)));
+  byId("periods-body").replaceChildren(...periods.slice().reverse().map((p) => el("tr", {},
+    el("td", {}, p.name),
+    ...kinds.map((k) => el("td", { class: "number " + colorClass(p.card.usage[k].color) }, p.card.usage[k].value)),
+    el("td", { class: "number" }, p.card.cost.value))));
+}
+
+function formatDuration(seconds) {
+  const m = Math.floor(seconds / 60);
+  const s = Math.round(seconds % 60);
+  return m + ":" + String(s).padStart(2, "0");
+}
+
+// renderCalls shows the calls that match the search term.
+function renderCalls() {
+  const labels = data.labels;
+  byId("calls-title").textContent = labels.calls;
+  byId("search").placeholder = labels.search;
+  byId("calls-head").replaceChildren(
+    ...[labels.time, labels.number, labels.partner, labels.duration, labels.kind].map((l) => el("th", {}, l)));
+  const term = byId("search").value.trim().toLowerCase();
+  const calls = account.calls
+    .filter((c) => !term || c.number.toLowerCase().includes(term) || c.partner.toLowerCase().includes(term))
+    .slice(0, maxCalls);
+  byId("calls-body").replaceChildren(...calls.map((c) => el("tr", {},
+    el("td", {}, new Date(c.time).toLocaleString()),
+    el("td", {}, c.number),
+    el("td", {}, c.partner),
+    el("td", { class: "number" }, formatDuration(c.duration_seconds)),
+    el("td", {}, labels[c.kind] || c.kind))));
+}
+
+byId("login").addEventListener("submit", (e) => {
+  e.preventDefault();
+  localStorage.setItem(tokenKey, byId("token").value);
+  byId("token").value = "";
+  load();
+});
+byId("account").addEventListener("change", render);
+byId("search").addEventListener("input", renderCalls);
+
+load();
+// The usage is cached by the server, so reloading more often does not show newer data.
+setInterval(() => {
+  if (data) {
+    load();
+  }
+}, 5 * 60 * 1000);
@@ -7,6 +7,9 @@ import (
 	"net/url"
 )
 
+// PortalURL is the URL of the easyBell customer portal.
+const PortalURL = "https://login.easybell.de/"
+
 // Client is the main type allowing you to interact with the easyBell service.
 // A client maintains an authenticated connection via cookies.
 type Client struct {
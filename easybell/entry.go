@@ -3,8 +3,21 @@ package easybell
 import (
 	"encoding/json"
 	"time"
+	// easyBell timestamps must be interpreted in German local time regardless of the zoneinfo available on the host.
+	_ "time/tzdata"
 )
 
+// Location is the time zone in which the easyBell API reports timestamps.
+// The time zone database is embedded so that Location is available on every platform.
+var Location *time.Location
+
+func init() {
+	var err error
+	if Location, err = time.LoadLocation("Europe/Berlin"); err != nil {
+		panic(err)
+	}
+}
+
 // These constants identify known direction filters of the easyBell API.
 const (
 	CallDirectionAny                = "*"
@@ -36,6 +49,7 @@ const (
 )
 
 // A CallLogEntry represents a single call as returned from the easyBell API.
+// The Time of an entry is in the time zone [Location].
 type CallLogEntry struct {
 	ID             string
 	Deleted        string
@@ -82,7 +96,7 @@ func (e *CallLogEntry) UnmarshalJSON(data []byte) (err error) {
 		FaxStatus:      aux.FaxStatus,
 		FaxErrorReason: aux.FaxErrorReason,
 	}
-	if e.Time, err = time.Parse("02.01.2006 15:04:05", string(aux.Time)); err != nil {
+	if e.Time, err = time.ParseInLocation("02.01.2006 15:04:05", aux.Time, Location); err != nil {
 		return err
 	}
 	return nil
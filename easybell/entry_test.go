@@ -0,0 +1,54 @@
+package easybell
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestCallLogEntryUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		datum string
+		// want contains the valid instants in UTC.
+		// Wall clock times that do not exist or occur twice have two valid instants
+		// because the time package does not guarantee which offset is chosen.
+		want []string
+	}{
+		{"winter time", "15.01.2026 12:00:00", []string{"2026-01-15T11:00:00Z"}},
+		{"summer time", "15.07.2026 12:00:00", []string{"2026-07-15T10:00:00Z"}},
+		{"before spring forward", "29.03.2026 01:59:59", []string{"2026-03-29T00:59:59Z"}},
+		{"in the gap of spring forward", "29.03.2026 02:30:00", []string{"2026-03-29T00:30:00Z", "2026-03-29T01:30:00Z"}},
+		{"after spring forward", "29.03.2026 03:00:00", []string{"2026-03-29T01:00:00Z"}},
+		{"before fall back", "25.10.2026 01:59:59", []string{"2026-10-24T23:59:59Z"}},
+		{"start of the repeated hour", "25.10.2026 02:00:00", []string{"2026-10-25T00:00:00Z", "2026-10-25T01:00:00Z"}},
+		{"in the repeated hour", "25.10.2026 02:30:00", []string{"2026-10-25T00:30:00Z", "2026-10-25T01:30:00Z"}},
+		{"end of the repeated hour", "25.10.2026 02:59:59", []string{"2026-10-25T00:59:59Z", "2026-10-25T01:59:59Z"}},
+		{"after fall back", "25.10.2026 03:00:00", []string{"2026-10-25T02:00:00Z"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var e CallLogEntry
+			if err := json.Unmarshal([]byte(`{"ID": "1", "DATUM": "`+tt.datum+`", "DAUER": 90}`), &e); err != nil {
+				t.Fatal(err)
+			}
+			if got := e.Time.UTC().Format(time.RFC3339); !slices.Contains(tt.want, got) {
+				t.Errorf("Time = %s, want one of %v", got, tt.want)
+			}
+			if e.Time.Location() != Location {
+				t.Errorf("Location = %s, want %s", e.Time.Location(), Location)
+			}
+			if e.Duration != 90*time.Second {
+				t.Errorf("Duration = %s, want 1m30s", e.Duration)
+			}
+		})
+	}
+}
+
+func TestCallLogEntryUnmarshalJSONInvalidTime(t *testing.T) {
+	var e CallLogEntry
+	if err := json.Unmarshal([]byte(`{"DATUM": "2026-03-29 02:30:00"}`), &e); err == nil {
+		t.Error("expected an error for a timestamp in the wrong format")
+	}
+}
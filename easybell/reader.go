@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"io"
+	"net/http"
 	"net/url"
 	"strconv"
 	"time"
@@ -13,6 +14,10 @@ import (
 // If you receive this error you can correct the filter values and call [CallLogReader.Read] again.
 var ErrBadFilter = errors.New("bad filter")
 
+// ErrNotAuthenticated indicates that the session of a client is not authenticated, e.g. because it has expired.
+// If you receive this error you can call [Client.Login] and read again.
+var ErrNotAuthenticated = errors.New("not authenticated")
+
 // NewCallLogReader creates a new reader that reads the call log entries in the specified time frame.
 func NewCallLogReader(c *Client, start, end time.Time) *CallLogReader {
 	return &CallLogReader{
@@ -59,6 +64,9 @@ func (r *CallLogReader) Reset(start, end time.Time) {
 // Read reads the next call log entry and returns it.
 // If the read buffer is empty this method fetches the next page of calls from the easyBell API.
 // If all calls have been read, the error will be io.EOF.
+//
+// The returned entry is only valid until the next page is fetched.
+// Copy the entry if you need to retain it.
 func (r *CallLogReader) Read() (*CallLogEntry, error) {
 	if r.i >= len(r.buf) {
 		if err := r.nextPage(); err != nil {
@@ -108,6 +116,10 @@ func (r *CallLogReader) nextPage() error {
 	defer func() {
 		_ = resp.Body.Close()
 	}()
+	// Without a valid session the portal redirects to the login page.
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden || resp.Request.URL.Path != "/call-history/data" {
+		return ErrNotAuthenticated
+	}
 
 	decoder := json.NewDecoder(resp.Body)
 	// There is a "last_page" field in the response.
@@ -128,6 +140,22 @@ func (r *CallLogReader) nextPage() error {
 	return nil
 }
 
+// ReadAll reads all calls from r and returns them.
+// When all calls have been read, the error will be nil.
+// In particular io.EOF is not considered an error for this function.
+func (r *CallLogReader) ReadAll() (entries []CallLogEntry, err error) {
+	var entry *CallLogEntry
+	for {
+		if entry, err = r.Read(); err != nil {
+			if errors.Is(err, io.EOF) {
+				err = nil
+			}
+			return
+		}
+		entries = append(entries, *entry)
+	}
+}
+
 // ReadUsage reads all calls from r and aggregates the used call minutes into a Usage value.
 // When all calls have been read, the error will be nil.
 // In particular io.EOF is not considered an error for this function.
@@ -140,16 +168,7 @@ func (r *CallLogReader) ReadUsage() (u Usage, err error) {
 			}
 			return
 		}
-		switch entry.Kind {
-		case CallKindNational:
-			u.National += entry.Duration
-		case CallKindMobile:
-			u.Mobile += entry.Duration
-		case CallKindInternational:
-			u.Other += entry.Duration
-		default:
-			u.Other += entry.Duration
-		}
+		u.Add(entry)
 	}
 }
 
@@ -160,6 +179,38 @@ type Usage struct {
 	Other    time.Duration
 }
 
+// Add adds the duration of the call e to the matching field of u.
+func (u *Usage) Add(e *CallLogEntry) {
+	switch e.Kind {
+	case CallKindNational:
+		u.National += e.Duration
+	case CallKindMobile:
+		u.Mobile += e.Duration
+	case CallKindInternational:
+		u.Other += e.Duration
+	default:
+		u.Other += e.Duration
+	}
+}
+
+// Plus returns the sum of u and v.
+func (u Usage) Plus(v Usage) Usage {
+	return Usage{
+		National: u.National + v.National,
+		Mobile:   u.Mobile + v.Mobile,
+		Other:    u.Other + v.Other,
+	}
+}
+
+// Scale returns u with every field multiplied by f.
+func (u Usage) Scale(f float64) Usage {
+	return Usage{
+		National: time.Duration(float64(u.National) * f),
+		Mobile:   time.Duration(float64(u.Mobile) * f),
+		Other:    time.Duration(float64(u.Other) * f),
+	}
+}
+
 // Total calculates the total phone time of u.
 func (u Usage) Total() time.Duration {
 	return u.National + u.Mobile + u.Other
@@ -0,0 +1,77 @@
+package easybell
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// redirectTransport sends all requests to the server at target.
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (t redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme, req.URL.Host = t.target.Scheme, t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// testClient returns a client whose requests are handled by handler.
+func testClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	target, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewClient()
+	c.httpClient.Transport = redirectTransport{target}
+	return c
+}
+
+func TestCallLogReaderNotAuthenticated(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		want    error
+	}{
+		{"redirect to login", func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/login" {
+				_, _ = w.Write([]byte("<html></html>"))
+				return
+			}
+			http.Redirect(w, r, "/login", http.StatusFound)
+		}, ErrNotAuthenticated},
+		{"unauthorized", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}, ErrNotAuthenticated},
+		{"forbidden", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}, ErrNotAuthenticated},
+		{"bad filter", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"last_page": 0, "data": []}`))
+		}, ErrBadFilter},
+		{"valid response", func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("page") == "1" {
+				_, _ = w.Write([]byte(`{"last_page": 1, "data": [{"id": "1", "DATUM": "15.01.2026 12:00:00", "DAUER": 90}]}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"last_page": 1, "data": []}`))
+		}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := testClient(t, tt.handler)
+			reader := NewCallLogReader(c, time.Now().Add(-time.Hour), time.Now())
+			_, err := reader.ReadAll()
+			if !errors.Is(err, tt.want) {
+				t.Errorf("ReadAll() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
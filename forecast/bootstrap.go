@@ -0,0 +1,117 @@
+package forecast
+
+import (
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	"github.com/lmr-hh/easybell-billing-info/easybell"
+)
+
+// DefaultSamples is the number of bootstrap samples used by [Evaluate].
+const DefaultSamples = 1000
+
+// A Result is a forecast including its uncertainty.
+type Result struct {
+	// Estimate is the point estimate of the usage at the end of the period.
+	Estimate easybell.Usage
+	// Low and High delimit the confidence interval of the estimate.
+	Low  easybell.Usage
+	High easybell.Usage
+	// Level is the confidence level of the interval, e.g. 0.8.
+	Level float64
+	// NationalExhausted and MobileExhausted are the days on which the respective quota is projected to be exhausted.
+	// The zero value indicates that the quota is not exhausted within the period.
+	NationalExhausted time.Time
+	MobileExhausted   time.Time
+}
+
+// Evaluate runs m on in and calculates the confidence interval at the specified level
+// as well as the days on which the quotas are exhausted.
+// A quota of 0 is never exhausted.
+func Evaluate(m Model, in Input, level float64, nationalQuota, mobileQuota time.Duration) Result {
+	r := Result{
+		Estimate: m.Forecast(in),
+		Level:    level,
+	}
+	r.Low, r.High = Bootstrap(m, in, DefaultSamples, level, nil)
+	r.NationalExhausted = Exhaustion(m, in, nationalQuota, func(u easybell.Usage) time.Duration { return u.National })
+	r.MobileExhausted = Exhaustion(m, in, mobileQuota, func(u easybell.Usage) time.Duration { return u.Mobile })
+	return r
+}
+
+// Bootstrap estimates a confidence interval for the forecast of m.
+// The history of in is resampled n times and the model is evaluated on every sample.
+// Full days are only replaced by days of the same weekday so that weekly patterns are preserved.
+// If rng is nil, a generator with a fixed seed is used so that results are reproducible.
+func Bootstrap(m Model, in Input, n int, level float64, rng *rand.Rand) (low, high easybell.Usage) {
+	if rng == nil {
+		rng = rand.New(rand.NewPCG(1, 2))
+	}
+	var pools [7][]easybell.Usage
+	for _, d := range in.History {
+		if d.Fraction() == 1 {
+			pools[d.Start.Weekday()] = append(pools[d.Start.Weekday()], d.Usage)
+		}
+	}
+
+	samples := make([]easybell.Usage, n)
+	sample := in
+	sample.History = slices.Clone(in.History)
+	for i := range samples {
+		for j, d := range in.History {
+			if pool := pools[d.Start.Weekday()]; d.Fraction() == 1 && len(pool) > 0 {
+				sample.History[j].Usage = pool[rng.IntN(len(pool))]
+			}
+		}
+		samples[i] = m.Forecast(sample)
+	}
+
+	alpha := (1 - level) / 2
+	quantiles := func(field func(*easybell.Usage) *time.Duration) {
+		values := make([]time.Duration, len(samples))
+		for i := range samples {
+			values[i] = *field(&samples[i])
+		}
+		slices.Sort(values)
+		*field(&low) = quantile(values, alpha)
+		*field(&high) = quantile(values, 1-alpha)
+	}
+	quantiles(func(u *easybell.Usage) *time.Duration { return &u.National })
+	quantiles(func(u *easybell.Usage) *time.Duration { return &u.Mobile })
+	quantiles(func(u *easybell.Usage) *time.Duration { return &u.Other })
+	return low, high
+}
+
+// quantile returns the q-quantile of the sorted values.
+func quantile(values []time.Duration, q float64) time.Duration {
+	if len(values) == 0 {
+		return 0
+	}
+	i := int(q * float64(len(values)-1))
+	return values[min(max(i, 0), len(values)-1)]
+}
+
+// Exhaustion returns the day on which the usage selected by field is projected to reach quota.
+// The projection evaluates m for the end of every remaining day of the period.
+// If the quota is already exhausted, the day of in.Now is returned.
+// If the quota is not exhausted within the period or quota is 0, the zero time is returned.
+func Exhaustion(m Model, in Input, quota time.Duration, field func(easybell.Usage) time.Duration) time.Time {
+	if quota <= 0 {
+		return time.Time{}
+	}
+	if field(in.Current) >= quota {
+		return startOfDay(in.Now)
+	}
+	partial := in
+	for day := startOfDay(in.Now); day.Before(in.End); day = day.AddDate(0, 0, 1) {
+		partial.End = day.AddDate(0, 0, 1)
+		if partial.End.After(in.End) {
+			partial.End = in.End
+		}
+		if field(m.Forecast(partial)) >= quota {
+			return day
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,196 @@
+// Package forecast estimates the phone usage at the end of a billing period.
+//
+// A [Model] receives the usage of the current period so far and the daily usage of a
+// recent time window and projects the usage at the end of the period.
+package forecast
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lmr-hh/easybell-billing-info/easybell"
+	"github.com/lmr-hh/easybell-billing-info/holiday"
+)
+
+// These constants identify the available forecast models.
+const (
+	ModelLinear  = "linear"
+	ModelWeekday = "weekday"
+)
+
+// Models contains the names of all available models.
+var Models = []string{ModelLinear, ModelWeekday}
+
+// A Day holds the usage of a single calendar day.
+// The first and last day of a history may be partial days.
+type Day struct {
+	// Start and End delimit the time frame of the day that is covered by Usage.
+	Start time.Time
+	End   time.Time
+	Usage easybell.Usage
+}
+
+// Fraction returns the fraction of the calendar day that is covered by d.
+func (d Day) Fraction() float64 {
+	return float64(d.End.Sub(d.Start)) / float64(dayLength(d.Start))
+}
+
+// Input contains the data available to a forecast model.
+type Input struct {
+	// Start and End delimit the billing period.
+	Start time.Time
+	End   time.Time
+	// Now is the point in time of the forecast.
+	Now time.Time
+	// Current is the usage between Start and Now.
+	Current easybell.Usage
+	// Window is the length of the time frame before Now that is covered by History.
+	Window time.Duration
+	// History contains the daily usage between Now-Window and Now, oldest first.
+	History []Day
+}
+
+// A Model projects the usage at the end of a billing period.
+type Model interface {
+	// Forecast returns the estimated usage at in.End.
+	Forecast(in Input) easybell.Usage
+}
+
+// New returns the model with the specified name.
+// The holiday calendar is used by models that distinguish working days from holidays.
+func New(name string, holidays holiday.Calendar) (Model, error) {
+	switch name {
+	case ModelLinear:
+		return Linear{}, nil
+	case ModelWeekday:
+		return Weekday{Holidays: holidays}, nil
+	default:
+		return nil, fmt.Errorf("unknown forecast model %q", name)
+	}
+}
+
+// Days aggregates the entries into daily usage between from and to.
+// Calendar days are determined in the location of from.
+// Entries outside the time frame are ignored.
+func Days(entries []easybell.CallLogEntry, from, to time.Time) []Day {
+	var days []Day
+	for start := from; start.Before(to); {
+		end := startOfDay(start).AddDate(0, 0, 1)
+		if end.After(to) {
+			end = to
+		}
+		days = append(days, Day{Start: start, End: end})
+		start = end
+	}
+	for i := range entries {
+		t := entries[i].Time
+		if t.Before(from) || !t.Before(to) {
+			continue
+		}
+		for j := range days {
+			if t.Before(days[j].End) {
+				days[j].Usage.Add(&entries[i])
+				break
+			}
+		}
+	}
+	return days
+}
+
+// Sum returns the total usage of days.
+func Sum(days []Day) (u easybell.Usage) {
+	for _, d := range days {
+		u = u.Plus(d.Usage)
+	}
+	return u
+}
+
+// Linear is a model that extrapolates the usage of the history window linearly to the length of the billing period.
+// The usage of the period so far is not taken into account.
+type Linear struct{}
+
+// Forecast implements the [Model] interface.
+func (Linear) Forecast(in Input) easybell.Usage {
+	return Sum(in.History).Scale(in.End.Sub(in.Start).Hours() / in.Window.Hours())
+}
+
+// Weekday is a model that projects the remaining usage of the billing period from
+// the average usage per weekday in the history window.
+// Public holidays are treated as a separate kind of day.
+type Weekday struct {
+	Holidays holiday.Calendar
+}
+
+// holidayIndex is the index of the holiday average in Weekday.averages.
+const holidayIndex = 7
+
+// Forecast implements the [Model] interface.
+func (m Weekday) Forecast(in Input) easybell.Usage {
+	averages := m.averages(in.History)
+	estimate := in.Current
+	for start := in.Now; start.Before(in.End); {
+		day := startOfDay(start)
+		end := day.AddDate(0, 0, 1)
+		if end.After(in.End) {
+			end = in.End
+		}
+		fraction := float64(end.Sub(start)) / float64(dayLength(day))
+		estimate = estimate.Plus(averages[m.class(day)].Scale(fraction))
+		start = end
+	}
+	return estimate
+}
+
+// class returns the index of the average that applies to the day t.
+func (m Weekday) class(t time.Time) int {
+	if m.Holidays.IsHoliday(t) {
+		return holidayIndex
+	}
+	return int(t.Weekday())
+}
+
+// averages calculates the average usage of a full day for every weekday and for holidays.
+// Kinds of days without data fall back to the overall average, holidays fall back to Sundays.
+func (m Weekday) averages(history []Day) (averages [holidayIndex + 1]easybell.Usage) {
+	var (
+		sums    [holidayIndex + 1]easybell.Usage
+		weights [holidayIndex + 1]float64
+		total   easybell.Usage
+		weight  float64
+	)
+	for _, d := range history {
+		c := m.class(d.Start)
+		sums[c] = sums[c].Plus(d.Usage)
+		weights[c] += d.Fraction()
+		total = total.Plus(d.Usage)
+		weight += d.Fraction()
+	}
+	var overall easybell.Usage
+	if weight > 0 {
+		overall = total.Scale(1 / weight)
+	}
+	for c := range averages {
+		switch {
+		case weights[c] > 0:
+			averages[c] = sums[c].Scale(1 / weights[c])
+		case c == holidayIndex:
+			averages[c] = averages[time.Sunday]
+		default:
+			averages[c] = overall
+		}
+	}
+	return averages
+}
+
+// startOfDay returns midnight of the calendar day of t in the location of t.
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// dayLength returns the length of the calendar day of t.
+// Days of daylight saving time transitions are shorter or longer than 24 hours.
+func dayLength(t time.Time) time.Duration {
+	day := startOfDay(t)
+	return day.AddDate(0, 0, 1).Sub(day)
+}
@@ -0,0 +1,158 @@
+// Package holiday implements a calendar of German public holidays.
+//
+// Public holidays in Germany are partly defined by the federal states (Bundesländer).
+// A [Calendar] contains the nationwide holidays and the holidays of a single state.
+// Holidays that only apply to some municipalities of a state (e.g. Mariä Himmelfahrt in Bavaria)
+// are not included.
+package holiday
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// These constants identify the German federal states by their official abbreviations.
+const (
+	BadenWuerttemberg     = "BW"
+	Bayern                = "BY"
+	Berlin                = "BE"
+	Brandenburg           = "BB"
+	Bremen                = "HB"
+	Hamburg               = "HH"
+	Hessen                = "HE"
+	MecklenburgVorpommern = "MV"
+	Niedersachsen         = "NI"
+	NordrheinWestfalen    = "NW"
+	RheinlandPfalz        = "RP"
+	Saarland              = "SL"
+	Sachsen               = "SN"
+	SachsenAnhalt         = "ST"
+	SchleswigHolstein     = "SH"
+	Thueringen            = "TH"
+)
+
+// States contains the abbreviations of all German federal states.
+var States = []string{
+	BadenWuerttemberg, Bayern, Berlin, Brandenburg, Bremen, Hamburg, Hessen, MecklenburgVorpommern,
+	Niedersachsen, NordrheinWestfalen, RheinlandPfalz, Saarland, Sachsen, SachsenAnhalt, SchleswigHolstein, Thueringen,
+}
+
+// A Holiday is a single public holiday.
+type Holiday struct {
+	// Date is midnight UTC of the day of the holiday.
+	Date time.Time
+	Name string
+}
+
+// A Calendar determines the public holidays of a federal state.
+// The zero value is a calendar that contains only the nationwide holidays.
+type Calendar struct {
+	// State is the abbreviation of a federal state, e.g. "HH".
+	State string
+}
+
+// NewCalendar returns a calendar for the specified state.
+// An empty state returns a calendar with only the nationwide holidays.
+func NewCalendar(state string) (Calendar, error) {
+	state = strings.ToUpper(state)
+	if state != "" && !slices.Contains(States, state) {
+		return Calendar{}, fmt.Errorf("unknown federal state %q", state)
+	}
+	return Calendar{State: state}, nil
+}
+
+// IsHoliday indicates whether the calendar day of t is a public holiday.
+// The day is determined in the location of t.
+func (c Calendar) IsHoliday(t time.Time) bool {
+	year, month, day := t.Date()
+	date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	for _, h := range c.Holidays(year) {
+		if h.Date.Equal(date) {
+			return true
+		}
+	}
+	return false
+}
+
+// Holidays returns the public holidays in the specified year ordered by date.
+func (c Calendar) Holidays(year int) []Holiday {
+	easter := Easter(year)
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	in := func(states ...string) bool {
+		return slices.Contains(states, c.State)
+	}
+
+	holidays := []Holiday{
+		{date(time.January, 1), "Neujahr"},
+		{easter.AddDate(0, 0, -2), "Karfreitag"},
+		{easter.AddDate(0, 0, 1), "Ostermontag"},
+		{date(time.May, 1), "Tag der Arbeit"},
+		{easter.AddDate(0, 0, 39), "Christi Himmelfahrt"},
+		{easter.AddDate(0, 0, 50), "Pfingstmontag"},
+		{date(time.October, 3), "Tag der Deutschen Einheit"},
+		{date(time.December, 25), "1. Weihnachtstag"},
+		{date(time.December, 26), "2. Weihnachtstag"},
+	}
+	if in(BadenWuerttemberg, Bayern, SachsenAnhalt) {
+		holidays = append(holidays, Holiday{date(time.January, 6), "Heilige Drei Könige"})
+	}
+	if (in(Berlin) && year >= 2019) || (in(MecklenburgVorpommern) && year >= 2023) {
+		holidays = append(holidays, Holiday{date(time.March, 8), "Internationaler Frauentag"})
+	}
+	if in(Brandenburg) {
+		holidays = append(holidays,
+			Holiday{easter, "Ostersonntag"},
+			Holiday{easter.AddDate(0, 0, 49), "Pfingstsonntag"},
+		)
+	}
+	if in(BadenWuerttemberg, Bayern, Hessen, NordrheinWestfalen, RheinlandPfalz, Saarland) {
+		holidays = append(holidays, Holiday{easter.AddDate(0, 0, 60), "Fronleichnam"})
+	}
+	if in(Saarland) {
+		holidays = append(holidays, Holiday{date(time.August, 15), "Mariä Himmelfahrt"})
+	}
+	if in(Thueringen) && year >= 2019 {
+		holidays = append(holidays, Holiday{date(time.September, 20), "Weltkindertag"})
+	}
+	if year == 2017 || in(Brandenburg, MecklenburgVorpommern, Sachsen, SachsenAnhalt, Thueringen) ||
+		(in(Bremen, Hamburg, Niedersachsen, SchleswigHolstein) && year >= 2018) {
+		holidays = append(holidays, Holiday{date(time.October, 31), "Reformationstag"})
+	}
+	if in(BadenWuerttemberg, Bayern, NordrheinWestfalen, RheinlandPfalz, Saarland) {
+		holidays = append(holidays, Holiday{date(time.November, 1), "Allerheiligen"})
+	}
+	if in(Sachsen) {
+		// Buß- und Bettag is the Wednesday before November 23rd.
+		nov22 := date(time.November, 22)
+		offset := (int(nov22.Weekday()) - int(time.Wednesday) + 7) % 7
+		holidays = append(holidays, Holiday{nov22.AddDate(0, 0, -offset), "Buß- und Bettag"})
+	}
+	slices.SortFunc(holidays, func(a, b Holiday) int {
+		return a.Date.Compare(b.Date)
+	})
+	return holidays
+}
+
+// Easter returns the date of Easter Sunday in the specified year as midnight UTC.
+// The date is calculated using the anonymous Gregorian algorithm.
+func Easter(year int) time.Time {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,93 @@
+package i18n
+
+// German is the German locale.
+var German = &Locale{
+	Name: "de",
+	messages: map[string]string{
+		"heading.current":  "easyBell Telefonieverbrauch",
+		"heading.forecast": "easyBell Prognose zum Monatsende",
+		"heading.previous": "easyBell Monatsübersicht",
+		"title.all":        "Alle Konten · %s",
+		"period.range":     "%s – %s",
+
+		"account":          "Konto",
+		"national":         "Festnetz",
+		"mobile":           "Mobil",
+		"other":            "Andere",
+		"cost":             "Kosten",
+		"quota":            "%s (%s)",
+		"minutes":          "%s min.",
+		"minutes.range":    "%s – %s min.",
+		"additional_cost":  "Zusätzliche Kosten",
+		"additional_total": "Zusätzliche Kosten gesamt",
+		"more_accounts":    "%d weitere Konten",
+
+		"forecast":            "Prognose zum Monatsende",
+		"forecast.text":       "Diese Daten sind ein Schätzwert für den Telefonverbrauch am Monatsende. Sie beruhen auf den Daten der letzten %s Tage.",
+		"forecast.confidence": "Mit %s %% Wahrscheinlichkeit liegt der Verbrauch am Monatsende in den angegebenen Bereichen.",
+		"exhausted.national":  "Das Festnetz-Kontingent ist voraussichtlich am %s aufgebraucht.",
+		"exhausted.mobile":    "Das Mobil-Kontingent ist voraussichtlich am %s aufgebraucht.",
+		"international_calls": "Es sind in diesem Zeitraum internationale Anrufe getätigt worden. In der Kostenschätzung sind diese nicht berücksichtigt.",
+		"disclaimer":          "Diese Angaben sind Schätzwerte auf Basis der Anrufliste. Diese Angaben sollten mit dem Einzelverbindungsnachweis, bzw. der Rechnung des Monats abgeglichen werden.",
+
+		"alert.good":          "Verbrauch im Rahmen",
+		"alert.warning":       "Kontingent fast aufgebraucht",
+		"alert.attention":     "Kontingent überschritten",
+		"alert.quota":         "%s %s von %s (%s %%)",
+		"alert.international": "International %s",
+
+		"threshold.national": "Der Festnetz-Verbrauch hat %s %% des Kontingents erreicht (%s %%).",
+		"threshold.mobile":   "Der Mobil-Verbrauch hat %s %% des Kontingents erreicht (%s %%).",
+		"threshold.cost":     "Die voraussichtlichen zusätzlichen Kosten übersteigen %s (%s).",
+
+		"text.title":              "easyBell-Verbrauchsbericht für %s",
+		"text.current":            "Dieser Abrechnungszeitraum:",
+		"text.estimate":           "Geschätzter Verbrauch am Ende des Abrechnungszeitraums:",
+		"text.range":              "Prognosebereich (%s %% Wahrscheinlichkeit):",
+		"text.national":           "Festnetz",
+		"text.mobile":             "Mobil",
+		"text.international":      "International",
+		"text.exhausted.national": "Das Festnetz-Kontingent ist voraussichtlich am %s aufgebraucht.",
+		"text.exhausted.mobile":   "Das Mobil-Kontingent ist voraussichtlich am %s aufgebraucht.",
+		"text.basis":              "Die Schätzung beruht auf dem Modell %s und dem Verbrauch der letzten %s Tage.",
+		"text.forecast":           "Geschätzter Verbrauch am Ende des Abrechnungszeitraums",
+		"text.summary":            "Übersicht aller Konten",
+		"text.cost":               "Zusätzliche Kosten",
+		"text.total":              "Gesamt",
+
+		"backtest.title":     "easyBell-Prognose-Backtest vom %s bis %s",
+		"backtest.model":     "Modell",
+		"backtest.window":    "Zeitraum",
+		"backtest.forecasts": "Prognosen",
+		"backtest.days":      "%s T.",
+		"backtest.minutes":   "%s Min.",
+		"backtest.errors":    "MAE ist der mittlere absolute Fehler, MAPE der mittlere absolute prozentuale Fehler.",
+		"backtest.bias":      "Ein positiver Bias bedeutet, dass das Modell den Verbrauch überschätzt.",
+
+		"markdown.usage":    "Verbrauch",
+		"markdown.estimate": "Schätzung",
+		"markdown.range":    "Prognosebereich (%s %%)",
+		"markdown.quota":    "Inklusivvolumen",
+
+		"dashboard.title":    "easyBell Telefonieverbrauch",
+		"dashboard.daily":    "Verbrauch pro Tag",
+		"dashboard.periods":  "Letzte 12 Monate",
+		"dashboard.calls":    "Anrufe",
+		"dashboard.search":   "Nummer oder Gesprächspartner suchen",
+		"dashboard.time":     "Zeit",
+		"dashboard.number":   "Rufnummer",
+		"dashboard.partner":  "Gesprächspartner",
+		"dashboard.duration": "Dauer",
+		"dashboard.kind":     "Art",
+		"dashboard.updated":  "Stand",
+		"dashboard.period":   "Zeitraum",
+
+		"preview.title": "Kartenvorschau",
+	},
+	months:    [12]string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+	decimal:   ",",
+	thousands: ".",
+	currency:  "%s €",
+	date:      "%d. %s %d",
+	shortDate: "02.01.2006",
+}
@@ -0,0 +1,93 @@
+package i18n
+
+// English is the English locale.
+var English = &Locale{
+	Name: "en",
+	messages: map[string]string{
+		"heading.current":  "easyBell Phone Usage",
+		"heading.forecast": "easyBell Forecast for the End of the Month",
+		"heading.previous": "easyBell Monthly Report",
+		"title.all":        "All Accounts · %s",
+		"period.range":     "%s – %s",
+
+		"account":          "Account",
+		"national":         "National",
+		"mobile":           "Mobile",
+		"other":            "Other",
+		"cost":             "Cost",
+		"quota":            "%s (%s)",
+		"minutes":          "%s min.",
+		"minutes.range":    "%s – %s min.",
+		"additional_cost":  "Additional Cost",
+		"additional_total": "Total Additional Cost",
+		"more_accounts":    "%d more accounts",
+
+		"forecast":            "Forecast for the End of the Month",
+		"forecast.text":       "These values estimate the phone usage at the end of the month. They are based on the usage of the last %s days.",
+		"forecast.confidence": "With a probability of %s %% the usage at the end of the month is within the given ranges.",
+		"exhausted.national":  "The national quota will probably be exhausted on %s.",
+		"exhausted.mobile":    "The mobile quota will probably be exhausted on %s.",
+		"international_calls": "International calls were made in this period. They are not included in the cost estimate.",
+		"disclaimer":          "These values are estimates based on the call log. They should be compared with the itemized bill or the invoice of the month.",
+
+		"alert.good":          "Usage within quota",
+		"alert.warning":       "Quota almost exhausted",
+		"alert.attention":     "Quota exceeded",
+		"alert.quota":         "%s %s of %s (%s %%)",
+		"alert.international": "International %s",
+
+		"threshold.national": "National usage has reached %s %% of the quota (%s %%).",
+		"threshold.mobile":   "Mobile usage has reached %s %% of the quota (%s %%).",
+		"threshold.cost":     "The projected additional cost exceeds %s (%s).",
+
+		"text.title":              "EasyBell Usage Report for %s",
+		"text.current":            "This Billing Period:",
+		"text.estimate":           "Estimated Usage at the End of the Billing Period:",
+		"text.range":              "Forecast Range (%s %% Confidence):",
+		"text.national":           "National",
+		"text.mobile":             "Mobile",
+		"text.international":      "International",
+		"text.exhausted.national": "The national quota will be exhausted on %s.",
+		"text.exhausted.mobile":   "The mobile quota will be exhausted on %s.",
+		"text.basis":              "The estimate is based on the %s model using the usage of the last %s days.",
+		"text.forecast":           "Estimated Usage at the End of the Billing Period",
+		"text.summary":            "Summary of All Accounts",
+		"text.cost":               "Additional Cost",
+		"text.total":              "Total",
+
+		"backtest.title":     "EasyBell Forecast Backtest from %s to %s",
+		"backtest.model":     "Model",
+		"backtest.window":    "Estimate",
+		"backtest.forecasts": "Forecasts",
+		"backtest.days":      "%sd",
+		"backtest.minutes":   "%s min",
+		"backtest.errors":    "MAE is the mean absolute error, MAPE the mean absolute percentage error.",
+		"backtest.bias":      "A positive bias indicates that the model overestimates the usage.",
+
+		"markdown.usage":    "Usage",
+		"markdown.estimate": "Estimate",
+		"markdown.range":    "Forecast Range (%s %%)",
+		"markdown.quota":    "Quota",
+
+		"dashboard.title":    "easyBell Phone Usage",
+		"dashboard.daily":    "Usage per Day",
+		"dashboard.periods":  "Last 12 Months",
+		"dashboard.calls":    "Calls",
+		"dashboard.search":   "Search number or partner",
+		"dashboard.time":     "Time",
+		"dashboard.number":   "Number",
+		"dashboard.partner":  "Partner",
+		"dashboard.duration": "Duration",
+		"dashboard.kind":     "Kind",
+		"dashboard.updated":  "Updated",
+		"dashboard.period":   "Period",
+
+		"preview.title": "Card Preview",
+	},
+	months:    [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+	decimal:   ".",
+	thousands: ",",
+	currency:  "€%s",
+	date:      "%d %s %d",
+	shortDate: "2006-01-02",
+}
@@ -0,0 +1,107 @@
+// Package i18n provides the translations of the reports and locale-aware formatting of numbers, costs and dates.
+//
+// Every supported language has a message catalog that maps message keys to format strings for [fmt.Sprintf].
+package i18n
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A Locale contains the messages and formatting conventions of a language.
+// The methods of a nil *Locale use German, the original language of the reports.
+type Locale struct {
+	// Name is the name of the locale as used in the configuration, e.g. "de".
+	Name     string
+	messages map[string]string
+	months   [12]string
+	// decimal and thousands are the separators of formatted numbers.
+	decimal   string
+	thousands string
+	// currency formats an amount that is already formatted as a number.
+	currency string
+	// date formats a date from the day, the month name and the year.
+	date string
+	// shortDate is a layout for time.Format.
+	shortDate string
+}
+
+// Locales contains the names of all supported locales.
+var Locales = []string{German.Name, English.Name}
+
+// Lookup returns the locale with the specified name.
+func Lookup(name string) (*Locale, error) {
+	switch name {
+	case German.Name:
+		return German, nil
+	case English.Name:
+		return English, nil
+	default:
+		return nil, fmt.Errorf("unsupported locale %q, must be one of %s", name, strings.Join(Locales, ", "))
+	}
+}
+
+func (l *Locale) get() *Locale {
+	if l == nil {
+		return German
+	}
+	return l
+}
+
+// T returns the message with the specified key formatted with args.
+// If the catalog does not contain the key, the key itself is returned.
+func (l *Locale) T(key string, args ...any) string {
+	format, ok := l.get().messages[key]
+	if !ok {
+		return key
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// Number formats f with the specified number of fraction digits.
+func (l *Locale) Number(f float64, digits int) string {
+	l = l.get()
+	s := strconv.FormatFloat(math.Abs(f), 'f', digits, 64)
+	integer, fraction, _ := strings.Cut(s, ".")
+	var b strings.Builder
+	if f < 0 && strings.Trim(s, "0.") != "" {
+		b.WriteByte('-')
+	}
+	for i, c := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteString(l.thousands)
+		}
+		b.WriteRune(c)
+	}
+	if fraction != "" {
+		b.WriteString(l.decimal)
+		b.WriteString(fraction)
+	}
+	return b.String()
+}
+
+// Cost formats an amount in Euro, e.g. "2,52 €" or "€2.52".
+func (l *Locale) Cost(euros float64) string {
+	return fmt.Sprintf(l.get().currency, l.Number(euros, 2))
+}
+
+// Month returns the name of m.
+func (l *Locale) Month(m time.Month) string {
+	return l.get().months[m-1]
+}
+
+// Date formats the day of t including the name of the month, e.g. "1. Oktober 2026" or "1 October 2026".
+func (l *Locale) Date(t time.Time) string {
+	return fmt.Sprintf(l.get().date, t.Day(), l.Month(t.Month()), t.Year())
+}
+
+// ShortDate formats the day of t using digits only, e.g. "01.10.2026" or "2026-10-01".
+func (l *Locale) ShortDate(t time.Time) string {
+	return t.Format(l.get().shortDate)
+}
@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WriteInflux writes metrics to w in the InfluxDB line protocol.
+// Every metric is written as a measurement with a single field named value.
+// Labels become tags, labels with empty values are omitted because InfluxDB does not support empty tags.
+// Samples whose value is NaN or infinite are omitted because the line protocol cannot represent them.
+// All points have the timestamp t.
+func WriteInflux(w io.Writer, metrics []Metric, t time.Time) error {
+	b := bufio.NewWriter(w)
+	timestamp := strconv.FormatInt(t.UnixNano(), 10)
+	for _, m := range metrics {
+		for _, s := range m.Samples {
+			if math.IsNaN(s.Value) || math.IsInf(s.Value, 0) {
+				continue
+			}
+			b.WriteString(measurementEscaper.Replace(m.Name))
+			for _, l := range s.Labels {
+				if l.Value != "" {
+					b.WriteString("," + tagEscaper.Replace(l.Name) + "=" + tagEscaper.Replace(l.Value))
+				}
+			}
+			b.WriteString(" value=" + strconv.FormatFloat(s.Value, 'f', -1, 64) + " " + timestamp + "\n")
+		}
+	}
+	return b.Flush()
+}
+
+var (
+	measurementEscaper = strings.NewReplacer(`\`, `\\`, ",", `\,`, " ", `\ `)
+	tagEscaper         = strings.NewReplacer(`\`, `\\`, ",", `\,`, "=", `\=`, " ", `\ `)
+)
+
+// PostInflux sends metrics in the InfluxDB line protocol to the write endpoint at writeURL,
+// e.g. http://localhost:8086/api/v2/write?org=example&bucket=easybell&precision=ns.
+// If token is not empty, it is sent as API token.
+// The URL may contain credentials, so the returned errors do not contain it.
+func PostInflux(ctx context.Context, writeURL, token string, metrics []Metric, t time.Time) error {
+	var body bytes.Buffer
+	if err := WriteInflux(&body, metrics, t); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, writeURL, &body)
+	if err != nil {
+		return errors.New("invalid URL")
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if token != "" {
+		req.Header.Set("Authorization", "Token "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return fmt.Errorf("request failed: %w", urlErr.Err)
+		}
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected response status %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
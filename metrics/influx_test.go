@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteInflux(t *testing.T) {
+	metrics := append(sampleMetrics(), Metric{
+		Name: "easybell,usage ratio",
+		Type: TypeGauge,
+		Samples: []Sample{
+			{Labels: []Label{{"account", "Berlin"}}, Value: math.NaN()},
+			{Labels: []Label{{"account", "Hamburg"}}, Value: math.Inf(1)},
+			{Labels: []Label{{"account", "München"}}, Value: math.Inf(-1)},
+			{Labels: []Label{{"account", "Köln"}}, Value: 0.25},
+		},
+	})
+	var b strings.Builder
+	if err := WriteInflux(&b, metrics, time.Unix(1700000000, 5)); err != nil {
+		t.Fatal(err)
+	}
+	want := `easybell_usage_seconds,account=Berlin,kind=national value=5400 1700000000000000005
+easybell_usage_seconds,account=Ham\ "burg"\\,kind=mobile value=0.5 1700000000000000005
+easybell_usage_seconds,account=a\,\ b\=c\ d` + "\n" + `x value=-12 1700000000000000005
+easybell_up value=1 1700000000000000005
+easybell\,usage\ ratio,account=Köln value=0.25 1700000000000000005
+`
+	if got := b.String(); got != want {
+		t.Errorf("WriteInflux =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestPostInflux(t *testing.T) {
+	var body, token, contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body, token, contentType = string(b), r.Header.Get("Authorization"), r.Header.Get("Content-Type")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	metrics := sampleMetrics()[1:]
+	if err := PostInflux(context.Background(), server.URL+"/api/v2/write", "secret", metrics, time.Unix(10, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if want := "easybell_up value=1 10000000000\n"; body != want {
+		t.Errorf("body = %q, want %q", body, want)
+	}
+	if token != "Token secret" {
+		t.Errorf("Authorization = %q, want %q", token, "Token secret")
+	}
+	if !strings.HasPrefix(contentType, "text/plain") {
+		t.Errorf("Content-Type = %q, want text/plain", contentType)
+	}
+}
+
+func TestPostInfluxError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "partial write: field type conflict", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	writeURL := server.URL + "/api/v2/write?token=credential"
+	err := PostInflux(context.Background(), writeURL, "", sampleMetrics(), time.Unix(10, 0))
+	if err == nil {
+		t.Fatal("PostInflux succeeded with an error response")
+	}
+	if !strings.Contains(err.Error(), "field type conflict") {
+		t.Errorf("error %q does not contain the response", err)
+	}
+	if strings.Contains(err.Error(), "credential") {
+		t.Errorf("error %q contains the URL", err)
+	}
+}
@@ -0,0 +1,198 @@
+// Package metrics converts usage reports into metrics for time series databases.
+//
+// A [Snapshot] contains the reports of the current billing period of all accounts.
+// Its [Snapshot.Metrics] are channel-neutral and can be written in different formats,
+// e.g. the Prometheus text exposition format.
+package metrics
+
+import (
+	"time"
+
+	"github.com/lmr-hh/easybell-billing-info/easybell"
+	"github.com/lmr-hh/easybell-billing-info/report"
+)
+
+// A Snapshot is the result of fetching the usage of all accounts.
+type Snapshot struct {
+	// Time is the time at which the usage was fetched.
+	Time time.Time
+	// Duration is the time it took to fetch the usage of all accounts.
+	Duration time.Duration
+	Accounts []Account
+}
+
+// An Account is the usage of a single account in a snapshot.
+type Account struct {
+	// Name is the name of the account.
+	// If only a single account is configured, the name is empty.
+	Name string
+	// Report is the report of the current billing period including the forecast and the calls.
+	// If the usage could not be fetched, Report is nil.
+	Report *report.Report
+}
+
+// These constants identify the types of metrics.
+const (
+	TypeGauge = "gauge"
+)
+
+// A Metric is a named set of samples.
+type Metric struct {
+	Name    string
+	Help    string
+	Type    string
+	Samples []Sample
+}
+
+// A Sample is a single value of a metric.
+type Sample struct {
+	Labels []Label
+	Value  float64
+}
+
+// A Label is a dimension of a sample.
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Metrics returns the metrics of s.
+// Metrics without samples are omitted.
+func (s *Snapshot) Metrics() []Metric {
+	up := Metric{Name: "easybell_up", Help: "Whether the usage of the account could be fetched from easyBell.", Type: TypeGauge}
+	status := Metric{Name: "easybell_status", Help: "The status of the expected usage: 0 is good, 1 is warning, 2 is attention.", Type: TypeGauge}
+	start := Metric{Name: "easybell_period_start_timestamp_seconds", Help: "The start of the current billing period.", Type: TypeGauge}
+	end := Metric{Name: "easybell_period_end_timestamp_seconds", Help: "The end of the current billing period.", Type: TypeGauge}
+	usage := Metric{Name: "easybell_usage_seconds", Help: "The usage in the current billing period so far.", Type: TypeGauge}
+	quota := Metric{Name: "easybell_quota_seconds", Help: "The included quota.", Type: TypeGauge}
+	forecast := Metric{Name: "easybell_forecast_usage_seconds", Help: "The estimated usage at the end of the current billing period.", Type: TypeGauge}
+	cost := Metric{Name: "easybell_additional_cost_euros", Help: "The additional cost of the usage so far.", Type: TypeGauge}
+	forecastCost := Metric{Name: "easybell_forecast_additional_cost_euros", Help: "The estimated additional cost at the end of the current billing period.", Type: TypeGauge}
+	calls := Metric{Name: "easybell_calls", Help: "The number of calls in the current billing period so far.", Type: TypeGauge}
+
+	for _, a := range s.Accounts {
+		account := Label{"account", a.Name}
+		r := a.Report
+		if r == nil {
+			up.add(0, account)
+			continue
+		}
+		up.add(1, account)
+		status.add(float64(r.Status()), account)
+		start.add(float64(r.Start.Unix()), account)
+		end.add(float64(r.End.Unix()), account)
+		usage.addUsage(r.Usage, account)
+		quota.add(r.Tariff.NationalQuota.Seconds(), account, Label{"kind", "national"})
+		quota.add(r.Tariff.MobileQuota.Seconds(), account, Label{"kind", "mobile"})
+		cost.add(r.Tariff.Cost(r.Usage), account)
+		if f := r.Forecast; f != nil {
+			forecast.addUsage(f.Estimate, account, Label{"bound", "estimate"})
+			forecast.addUsage(f.Low, account, Label{"bound", "low"})
+			forecast.addUsage(f.High, account, Label{"bound", "high"})
+			forecastCost.add(r.Cost(), account)
+		}
+		if r.Calls != nil {
+			counts := make(map[string]int)
+			for _, c := range r.Calls {
+				counts[Kind(&c)]++
+			}
+			for _, kind := range Kinds {
+				calls.add(float64(counts[kind]), account, Label{"kind", kind})
+			}
+		}
+	}
+
+	scrape := []Metric{
+		{Name: "easybell_scrape_duration_seconds", Help: "The time it took to fetch the usage of all accounts.", Type: TypeGauge,
+			Samples: []Sample{{Value: s.Duration.Seconds()}}},
+		{Name: "easybell_scrape_timestamp_seconds", Help: "The time at which the usage was fetched.", Type: TypeGauge,
+			Samples: []Sample{{Value: float64(s.Time.UnixMilli()) / 1000}}},
+	}
+	var metrics []Metric
+	for _, m := range append(scrape, up, status, start, end, usage, quota, forecast, cost, forecastCost, calls) {
+		if len(m.Samples) > 0 {
+			metrics = append(metrics, m)
+		}
+	}
+	return metrics
+}
+
+// Kinds contains the values of the kind label of usage metrics.
+var Kinds = []string{"national", "mobile", "other"}
+
+// Kind returns the value of the kind label for the call e.
+// Calls are classified like in [easybell.Usage.Add].
+func Kind(e *easybell.CallLogEntry) string {
+	switch e.Kind {
+	case easybell.CallKindNational:
+		return "national"
+	case easybell.CallKindMobile:
+		return "mobile"
+	default:
+		return "other"
+	}
+}
+
+func (m *Metric) add(value float64, labels ...Label) {
+	m.Samples = append(m.Samples, Sample{labels, value})
+}
+
+// addUsage adds a sample for every kind of u.
+func (m *Metric) addUsage(u easybell.Usage, labels ...Label) {
+	for i, d := range []time.Duration{u.National, u.Mobile, u.Other} {
+		m.add(d.Seconds(), append(labels[:len(labels):len(labels)], Label{"kind", Kinds[i]})...)
+	}
+}
+
+// A Job is the state of a command that runs on a schedule.
+type Job struct {
+	Command  string
+	Schedule string
+	// LastRun is the time at which the last run started.
+	// The zero value indicates that the job has not run yet.
+	LastRun time.Time
+	// LastSuccess is the time at which the last successful run started.
+	// The zero value indicates that the job has not succeeded yet.
+	LastSuccess time.Time
+	// Duration is the time the last run took.
+	Duration time.Duration
+	// Success indicates whether the last run succeeded.
+	Success bool
+}
+
+// JobMetrics returns the metrics of jobs.
+// Jobs that have not run yet only report that they are scheduled.
+// Metrics without samples are omitted.
+func JobMetrics(jobs []Job) []Metric {
+	scheduled := Metric{Name: "easybell_job_scheduled", Help: "A command that runs on a schedule.", Type: TypeGauge}
+	success := Metric{Name: "easybell_job_success", Help: "Whether the last run of the job succeeded.", Type: TypeGauge}
+	lastRun := Metric{Name: "easybell_job_last_run_timestamp_seconds", Help: "The time at which the last run of the job started.", Type: TypeGauge}
+	lastSuccess := Metric{Name: "easybell_job_last_success_timestamp_seconds", Help: "The time at which the last successful run of the job started.", Type: TypeGauge}
+	duration := Metric{Name: "easybell_job_duration_seconds", Help: "The time the last run of the job took.", Type: TypeGauge}
+
+	for _, j := range jobs {
+		labels := []Label{{"command", j.Command}, {"schedule", j.Schedule}}
+		scheduled.add(1, labels...)
+		if j.LastRun.IsZero() {
+			continue
+		}
+		if j.Success {
+			success.add(1, labels...)
+		} else {
+			success.add(0, labels...)
+		}
+		lastRun.add(float64(j.LastRun.UnixMilli())/1000, labels...)
+		if !j.LastSuccess.IsZero() {
+			lastSuccess.add(float64(j.LastSuccess.UnixMilli())/1000, labels...)
+		}
+		duration.add(j.Duration.Seconds(), labels...)
+	}
+
+	var metrics []Metric
+	for _, m := range []Metric{scheduled, success, lastRun, lastSuccess, duration} {
+		if len(m.Samples) > 0 {
+			metrics = append(metrics, m)
+		}
+	}
+	return metrics
+}
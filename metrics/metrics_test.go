@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJobMetrics(t *testing.T) {
+	start := time.Date(2026, 10, 1, 6, 0, 0, 0, time.UTC)
+	jobs := []Job{
+		{Command: "alert", Schedule: "*/15 * * * *", LastRun: start, LastSuccess: start, Duration: 1500 * time.Millisecond, Success: true},
+		{Command: "last-month", Schedule: "0 8 1 * *", LastRun: start, Duration: 2 * time.Second},
+		{Command: "metrics", Schedule: "@hourly"},
+	}
+	var b strings.Builder
+	if err := WritePrometheus(&b, JobMetrics(jobs)); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP easybell_job_scheduled A command that runs on a schedule.
+# TYPE easybell_job_scheduled gauge
+easybell_job_scheduled{command="alert",schedule="*/15 * * * *"} 1
+easybell_job_scheduled{command="last-month",schedule="0 8 1 * *"} 1
+easybell_job_scheduled{command="metrics",schedule="@hourly"} 1
+# HELP easybell_job_success Whether the last run of the job succeeded.
+# TYPE easybell_job_success gauge
+easybell_job_success{command="alert",schedule="*/15 * * * *"} 1
+easybell_job_success{command="last-month",schedule="0 8 1 * *"} 0
+# HELP easybell_job_last_run_timestamp_seconds The time at which the last run of the job started.
+# TYPE easybell_job_last_run_timestamp_seconds gauge
+easybell_job_last_run_timestamp_seconds{command="alert",schedule="*/15 * * * *"} 1.7908344e+09
+easybell_job_last_run_timestamp_seconds{command="last-month",schedule="0 8 1 * *"} 1.7908344e+09
+# HELP easybell_job_last_success_timestamp_seconds The time at which the last successful run of the job started.
+# TYPE easybell_job_last_success_timestamp_seconds gauge
+easybell_job_last_success_timestamp_seconds{command="alert",schedule="*/15 * * * *"} 1.7908344e+09
+# HELP easybell_job_duration_seconds The time the last run of the job took.
+# TYPE easybell_job_duration_seconds gauge
+easybell_job_duration_seconds{command="alert",schedule="*/15 * * * *"} 1.5
+easybell_job_duration_seconds{command="last-month",schedule="0 8 1 * *"} 2
+`
+	if got := b.String(); got != want {
+		t.Errorf("JobMetrics =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestJobMetricsEmpty(t *testing.T) {
+	if m := JobMetrics(nil); len(m) != 0 {
+		t.Errorf("JobMetrics(nil) = %v, want no metrics", m)
+	}
+}
@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// PrometheusContentType is the content type of the Prometheus text exposition format.
+const PrometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// WritePrometheus writes metrics to w in the Prometheus text exposition format.
+func WritePrometheus(w io.Writer, metrics []Metric) error {
+	b := bufio.NewWriter(w)
+	for _, m := range metrics {
+		b.WriteString("# HELP " + m.Name + " " + helpEscaper.Replace(m.Help) + "\n")
+		b.WriteString("# TYPE " + m.Name + " " + m.Type + "\n")
+		for _, s := range m.Samples {
+			b.WriteString(m.Name)
+			if len(s.Labels) > 0 {
+				b.WriteByte('{')
+				for i, l := range s.Labels {
+					if i > 0 {
+						b.WriteByte(',')
+					}
+					b.WriteString(l.Name + `="` + labelEscaper.Replace(l.Value) + `"`)
+				}
+				b.WriteByte('}')
+			}
+			b.WriteString(" " + formatValue(s.Value) + "\n")
+		}
+	}
+	return b.Flush()
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+// formatValue formats v as a Prometheus sample value.
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
+
+// WriteTextfile writes metrics in the Prometheus text exposition format to the file at path,
+// e.g. for the textfile collector of the node exporter.
+// The file is replaced atomically so that the collector never reads a partially written file.
+func WriteTextfile(path string, metrics []Metric) error {
+	var b bytes.Buffer
+	if err := WritePrometheus(&b, metrics); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(f.Name())
+	}()
+	if _, err = f.Write(b.Bytes()); err == nil {
+		// Temporary files are only readable by the owner, but the collector may run as a different user.
+		err = f.Chmod(0o644)
+	}
+	if cErr := f.Close(); err == nil {
+		err = cErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
@@ -0,0 +1,117 @@
+package metrics
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// sampleMetrics returns metrics with labels and help texts that must be escaped.
+func sampleMetrics() []Metric {
+	return []Metric{
+		{
+			Name: "easybell_usage_seconds",
+			Help: "The usage in seconds.\nSee the \\docs.",
+			Type: TypeGauge,
+			Samples: []Sample{
+				{Labels: []Label{{"account", "Berlin"}, {"kind", "national"}}, Value: 5400},
+				{Labels: []Label{{"account", `Ham "burg"\`}, {"kind", "mobile"}}, Value: 0.5},
+				{Labels: []Label{{"account", "a, b=c d\nx"}, {"kind", ""}}, Value: -12},
+			},
+		},
+		{
+			Name: "easybell_up",
+			Help: "Whether the usage could be fetched.",
+			Type: TypeGauge,
+			Samples: []Sample{
+				{Value: 1},
+			},
+		},
+	}
+}
+
+func TestWritePrometheus(t *testing.T) {
+	var b strings.Builder
+	if err := WritePrometheus(&b, sampleMetrics()); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP easybell_usage_seconds The usage in seconds.\nSee the \\docs.
+# TYPE easybell_usage_seconds gauge
+easybell_usage_seconds{account="Berlin",kind="national"} 5400
+easybell_usage_seconds{account="Ham \"burg\"\\",kind="mobile"} 0.5
+easybell_usage_seconds{account="a, b=c d\nx",kind=""} -12
+# HELP easybell_up Whether the usage could be fetched.
+# TYPE easybell_up gauge
+easybell_up 1
+`
+	if got := b.String(); got != want {
+		t.Errorf("WritePrometheus =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	tests := []struct {
+		value float64
+		want  string
+	}{
+		{0, "0"},
+		{1.5, "1.5"},
+		{-3, "-3"},
+		{1e21, "1e+21"},
+		{math.Inf(1), "+Inf"},
+		{math.Inf(-1), "-Inf"},
+		{math.NaN(), "NaN"},
+	}
+	for _, tt := range tests {
+		if got := formatValue(tt.value); got != tt.want {
+			t.Errorf("formatValue(%g) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestWriteTextfile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "easybell.prom")
+	if err := os.WriteFile(path, []byte("old\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	metrics := sampleMetrics()
+	if err := WriteTextfile(path, metrics); err != nil {
+		t.Fatal(err)
+	}
+
+	var want strings.Builder
+	if err := WritePrometheus(&want, metrics); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want.String() {
+		t.Errorf("textfile contains\n%s\nwant\n%s", got, want.String())
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o644 {
+		t.Errorf("textfile has permissions %o, want 644", perm)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("directory contains %d files, want only the textfile", len(entries))
+	}
+}
+
+func TestWriteTextfileMissingDirectory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "easybell.prom")
+	if err := WriteTextfile(path, sampleMetrics()); err == nil {
+		t.Error("WriteTextfile succeeded in a missing directory")
+	}
+}
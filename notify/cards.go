@@ -0,0 +1,430 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/atc0005/go-teams-notify/v2/adaptivecard"
+
+	"github.com/lmr-hh/easybell-billing-info/cards"
+	"github.com/lmr-hh/easybell-billing-info/i18n"
+	"github.com/lmr-hh/easybell-billing-info/report"
+)
+
+// cardTemplateNames contains the names of the card templates per kind of report.
+var cardTemplateNames = map[report.Kind]string{
+	report.KindPrevious: "month.json",
+	report.KindCurrent:  "week.json",
+	report.KindSummary:  "summary.json",
+}
+
+// CardTemplates renders reports as Adaptive Cards from templates.
+//
+// The templates use a subset of the Adaptive Card Templating language:
+// ${expression} binds a value of the data model, $data repeats an element for every item of an array
+// and $when removes an element if its expression is false.
+// Expressions are paths like ${usage.national.value}, optionally negated with "!".
+// The special names $root, $data and $index are supported.
+//
+// The zero value and nil use the templates embedded from the cards directory of the repository.
+type CardTemplates struct {
+	// Dir is a directory that contains templates overriding the embedded ones.
+	// Templates that do not exist in Dir are read from the embedded templates.
+	Dir string
+}
+
+// Validate checks that the directory of t exists and that all templates can be read and are valid JSON.
+func (t *CardTemplates) Validate() error {
+	if t != nil && t.Dir != "" {
+		if info, err := os.Stat(t.Dir); err != nil {
+			return err
+		} else if !info.IsDir() {
+			return fmt.Errorf("%s is not a directory", t.Dir)
+		}
+	}
+	var errs []error
+	for _, name := range cardTemplateNames {
+		if _, err := t.template(name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Card renders r as an Adaptive Card in the language of l using the template for the kind of r.
+func (t *CardTemplates) Card(r *report.Report, l *i18n.Locale) (adaptivecard.Card, error) {
+	var card adaptivecard.Card
+	name := cardTemplateNames[r.Kind]
+	tmpl, err := t.template(name)
+	if err != nil {
+		return card, err
+	}
+	data, err := CardData(r, l)
+	if err != nil {
+		return card, err
+	}
+	expanded, _, err := expandTemplate(tmpl, templateScope{data, data, 0})
+	if err != nil {
+		return card, fmt.Errorf("%s: %w", name, err)
+	}
+	b, err := json.Marshal(expanded)
+	if err != nil {
+		return card, err
+	}
+	if err = json.Unmarshal(b, &card); err != nil {
+		return card, fmt.Errorf("%s: %w", name, err)
+	}
+	return card, nil
+}
+
+// CardData returns the data model to which the card templates are bound for r in the language of l.
+// The data model is a decoded JSON value so that it can be traversed by template expressions
+// and reused by other views of reports, e.g. the dashboard.
+func CardData(r *report.Report, l *i18n.Locale) (any, error) {
+	b, err := json.Marshal(newCardData(r, l))
+	if err != nil {
+		return nil, err
+	}
+	var data any
+	err = json.Unmarshal(b, &data)
+	return data, err
+}
+
+// template reads and decodes the template with the specified name.
+func (t *CardTemplates) template(name string) (any, error) {
+	var b []byte
+	err := fs.ErrNotExist
+	if t != nil && t.Dir != "" {
+		b, err = os.ReadFile(filepath.Join(t.Dir, name))
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		b, err = fs.ReadFile(cards.FS, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var tmpl any
+	if err = json.Unmarshal(b, &tmpl); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+// cardData is the data model of the card templates.
+type cardData struct {
+	Kind    report.Kind `json:"kind"`
+	Heading string      `json:"heading"`
+	Title   string      `json:"title"`
+	// Alerts describes the thresholds whose crossing triggered the report.
+	Alerts []string `json:"alerts"`
+	// Usage contains the usage in the period.
+	Usage cardGauges `json:"usage"`
+	// Forecast is only set for reports of the current period.
+	Forecast *cardForecast `json:"forecast"`
+	// Accounts contains the rows of a summary.
+	Accounts           []cardAccount `json:"accounts"`
+	Labels             cardLabels    `json:"labels"`
+	Cost               cardCost      `json:"cost"`
+	InternationalCalls string        `json:"internationalCalls"`
+	Disclaimer         string        `json:"disclaimer"`
+}
+
+// cardGauge is a single value of the usage.
+// The Color is an Adaptive Card color that reflects the status of the value.
+type cardGauge struct {
+	Label string `json:"label"`
+	Value string `json:"value"`
+	Range string `json:"range,omitempty"`
+	Color string `json:"color"`
+}
+
+type cardGauges struct {
+	National cardGauge `json:"national"`
+	Mobile   cardGauge `json:"mobile"`
+	Other    cardGauge `json:"other"`
+}
+
+type cardForecast struct {
+	cardGauges
+	Title      string `json:"title"`
+	Text       string `json:"text"`
+	Confidence string `json:"confidence"`
+	Exhaustion string `json:"exhaustion"`
+}
+
+type cardAccount struct {
+	Name string `json:"name"`
+	cardGauges
+	Cost string `json:"cost"`
+}
+
+// cardLabels contains the column headers of a summary.
+type cardLabels struct {
+	Account  string `json:"account"`
+	National string `json:"national"`
+	Mobile   string `json:"mobile"`
+	Other    string `json:"other"`
+	Cost     string `json:"cost"`
+}
+
+type cardCost struct {
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+// newCardData returns the data model of the card for r in the language of l.
+func newCardData(r *report.Report, l *i18n.Locale) cardData {
+	d := cardData{
+		Kind:    r.Kind,
+		Heading: heading(l, r),
+		Title:   title(l, r),
+		Alerts:  alertTexts(l, r),
+		Labels:  cardLabels{l.T("account"), l.T("national"), l.T("mobile"), l.T("other"), l.T("cost")},
+		Cost:    cardCost{l.T("additional_cost"), formatCost(l, r.Cost())},
+	}
+	minutes := func(d time.Duration) string { return formatMinutes(l, d) }
+	gauge := func(label string, d, quota time.Duration, value func(time.Duration) string, goodColor string) cardGauge {
+		return cardGauge{Label: label, Value: value(d), Color: minutesColor(d, quota, goodColor)}
+	}
+	switch r.Kind {
+	case report.KindSummary:
+		d.Cost.Label = l.T("additional_total")
+		for _, a := range r.Accounts {
+			u := a.ExpectedUsage()
+			d.Accounts = append(d.Accounts, cardAccount{
+				Name: a.Account,
+				cardGauges: cardGauges{
+					National: gauge("", u.National, a.Tariff.NationalQuota, minutes, adaptivecard.ColorDefault),
+					Mobile:   gauge("", u.Mobile, a.Tariff.MobileQuota, minutes, adaptivecard.ColorDefault),
+					Other:    gauge("", u.Other, 0, minutes, adaptivecard.ColorDefault),
+				},
+				Cost: formatCost(l, a.Cost()),
+			})
+		}
+	case report.KindCurrent:
+		f := r.Forecast
+		d.Usage = cardGauges{
+			National: gauge(l.T("national"), r.Usage.National, r.Tariff.NationalQuota, report.FormatDuration, adaptivecard.ColorDefault),
+			Mobile:   gauge(l.T("mobile"), r.Usage.Mobile, r.Tariff.MobileQuota, report.FormatDuration, adaptivecard.ColorDefault),
+			Other:    gauge(l.T("other"), r.Usage.Other, 0, report.FormatDuration, adaptivecard.ColorDefault),
+		}
+		d.Forecast = &cardForecast{
+			cardGauges: cardGauges{
+				National: gauge(quotaLabel(l, "national", r.Tariff.NationalQuota), f.Estimate.National, r.Tariff.NationalQuota, minutes, adaptivecard.ColorGood),
+				Mobile:   gauge(quotaLabel(l, "mobile", r.Tariff.MobileQuota), f.Estimate.Mobile, r.Tariff.MobileQuota, minutes, adaptivecard.ColorGood),
+				Other:    gauge(l.T("other"), f.Estimate.Other, 0, minutes, adaptivecard.ColorGood),
+			},
+			Title:      l.T("forecast"),
+			Text:       forecastText(l, f),
+			Confidence: confidenceText(l, f),
+			Exhaustion: exhaustionText(l, f),
+		}
+		d.Forecast.National.Range = formatRange(l, f.Low.National, f.High.National)
+		d.Forecast.Mobile.Range = formatRange(l, f.Low.Mobile, f.High.Mobile)
+		d.Forecast.Other.Range = formatRange(l, f.Low.Other, f.High.Other)
+	default:
+		d.Usage = cardGauges{
+			National: gauge(quotaLabel(l, "national", r.Tariff.NationalQuota), r.Usage.National, r.Tariff.NationalQuota, minutes, adaptivecard.ColorGood),
+			Mobile:   gauge(quotaLabel(l, "mobile", r.Tariff.MobileQuota), r.Usage.Mobile, r.Tariff.MobileQuota, minutes, adaptivecard.ColorGood),
+			Other:    gauge(l.T("other"), r.Usage.Other, 0, minutes, adaptivecard.ColorGood),
+		}
+		d.Disclaimer = l.T("disclaimer")
+	}
+	if r.Kind != report.KindSummary && r.Usage.Other > 0 {
+		d.InternationalCalls = l.T("international_calls")
+	}
+	return d
+}
+
+// minutesColor chooses a color for formatting d depending on how near d is to its quota.
+func minutesColor(d, quota time.Duration, goodColor string) string {
+	switch report.QuotaStatus(d, quota) {
+	case report.StatusGood:
+		return goodColor
+	case report.StatusWarning:
+		return adaptivecard.ColorWarning
+	default:
+		return adaptivecard.ColorAttention
+	}
+}
+
+// templateScope is the data that expressions in a template refer to.
+type templateScope struct {
+	data  any
+	root  any
+	index int
+}
+
+var (
+	// templateBinding matches a single binding expression in a string.
+	templateBinding = regexp.MustCompile(`\$\{([^}]*)\}`)
+	// templatePath matches the supported expressions.
+	templatePath = regexp.MustCompile(`^\s*(!?)\s*(\$root|\$data|\$index|[A-Za-z_][A-Za-z0-9_]*)((?:\.[A-Za-z0-9_]+)*)\s*$`)
+)
+
+// expandTemplate expands all binding expressions in v.
+// The returned bool is false if v is an element whose $when expression is false.
+// Elements of arrays that bind $data to an array are repeated for every item.
+func expandTemplate(v any, scope templateScope) (any, bool, error) {
+	switch v := v.(type) {
+	case map[string]any:
+		if expr, ok := v["$data"]; ok {
+			data, _, err := expandTemplate(expr, scope)
+			if err != nil {
+				return nil, false, err
+			}
+			scope = templateScope{data, scope.root, scope.index}
+		}
+		if expr, ok := v["$when"]; ok {
+			when, _, err := expandTemplate(expr, scope)
+			if err != nil {
+				return nil, false, err
+			}
+			if !truthy(when) {
+				return nil, false, nil
+			}
+		}
+		result := make(map[string]any, len(v))
+		for key, value := range v {
+			if key == "$data" || key == "$when" {
+				continue
+			}
+			expanded, keep, err := expandTemplate(value, scope)
+			if err != nil {
+				return nil, false, fmt.Errorf("%s: %w", key, err)
+			}
+			if keep {
+				result[key] = expanded
+			}
+		}
+		return result, true, nil
+	case []any:
+		result := make([]any, 0, len(v))
+		for i, item := range v {
+			items := []any{item}
+			scopes := []templateScope{scope}
+			if element, ok := item.(map[string]any); ok {
+				if expr, ok := element["$data"]; ok {
+					data, _, err := expandTemplate(expr, scope)
+					if err != nil {
+						return nil, false, fmt.Errorf("[%d]: %w", i, err)
+					}
+					if array, ok := data.([]any); ok {
+						items, scopes = nil, nil
+						repeated := make(map[string]any, len(element))
+						for key, value := range element {
+							if key != "$data" {
+								repeated[key] = value
+							}
+						}
+						for j, d := range array {
+							items = append(items, repeated)
+							scopes = append(scopes, templateScope{d, scope.root, j})
+						}
+					}
+				}
+			}
+			for j, item := range items {
+				expanded, keep, err := expandTemplate(item, scopes[j])
+				if err != nil {
+					return nil, false, fmt.Errorf("[%d]: %w", i, err)
+				}
+				if keep {
+					result = append(result, expanded)
+				}
+			}
+		}
+		return result, true, nil
+	case string:
+		if m := templateBinding.FindStringSubmatch(v); m != nil && m[0] == v {
+			value, err := evaluate(m[1], scope)
+			return value, true, err
+		}
+		var err error
+		s := templateBinding.ReplaceAllStringFunc(v, func(binding string) string {
+			value, e := evaluate(binding[2:len(binding)-1], scope)
+			if e != nil {
+				err = e
+			}
+			switch value := value.(type) {
+			case nil:
+				return ""
+			case string:
+				return value
+			default:
+				return fmt.Sprint(value)
+			}
+		})
+		return s, true, err
+	default:
+		return v, true, nil
+	}
+}
+
+// evaluate returns the value of the expression in scope.
+// Paths that do not exist evaluate to nil.
+func evaluate(expr string, scope templateScope) (any, error) {
+	m := templatePath.FindStringSubmatch(expr)
+	if m == nil {
+		return nil, fmt.Errorf("unsupported expression %q", expr)
+	}
+	var value any
+	switch m[2] {
+	case "$root":
+		value = scope.root
+	case "$data":
+		value = scope.data
+	case "$index":
+		value = float64(scope.index)
+	default:
+		value = lookup(scope.data, m[2])
+	}
+	if m[3] != "" {
+		for _, key := range strings.Split(m[3][1:], ".") {
+			value = lookup(value, key)
+		}
+	}
+	if m[1] == "!" {
+		return !truthy(value), nil
+	}
+	return value, nil
+}
+
+// lookup returns the field key of an object or the item with index key of an array.
+func lookup(v any, key string) any {
+	switch v := v.(type) {
+	case map[string]any:
+		return v[key]
+	case []any:
+		if i, err := strconv.Atoi(key); err == nil && i >= 0 && i < len(v) {
+			return v[i]
+		}
+	}
+	return nil
+}
+
+// truthy indicates whether v is considered true in a $when expression.
+func truthy(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case float64:
+		return v != 0
+	case []any:
+		return len(v) > 0
+	default:
+		return true
+	}
+}
@@ -0,0 +1,248 @@
+package notify
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lmr-hh/easybell-billing-info/easybell"
+	"github.com/lmr-hh/easybell-billing-info/i18n"
+	"github.com/lmr-hh/easybell-billing-info/report"
+)
+
+// decode decodes the JSON value s.
+func decode(t *testing.T, s string) any {
+	t.Helper()
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatalf("invalid JSON %s: %v", s, err)
+	}
+	return v
+}
+
+func TestExpandTemplate(t *testing.T) {
+	data := `{
+		"title": "March",
+		"count": 3,
+		"zero": 0,
+		"enabled": true,
+		"empty": "",
+		"none": null,
+		"list": [],
+		"usage": {"national": {"value": "12 min.", "color": "good"}},
+		"items": [{"name": "a", "cost": 1}, {"name": "b", "cost": 2}],
+		"alerts": ["first", "second"]
+	}`
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{"literal", `{"text": "Hello", "size": 3, "wrap": true}`, `{"text": "Hello", "size": 3, "wrap": true}`},
+		{"string binding", `{"text": "${title}"}`, `{"text": "March"}`},
+		{"binding within text", `{"text": "Usage in ${title}: ${count} calls"}`, `{"text": "Usage in March: 3 calls"}`},
+		{"number binding keeps the type", `{"value": "${count}"}`, `{"value": 3}`},
+		{"bool binding keeps the type", `{"value": "${enabled}"}`, `{"value": true}`},
+		{"object binding keeps the type", `{"value": "${usage.national}"}`, `{"value": {"value": "12 min.", "color": "good"}}`},
+		{"nested lookup", `{"text": "${usage.national.value}", "color": "${usage.national.color}"}`, `{"text": "12 min.", "color": "good"}`},
+		{"array index", `{"text": "${items.1.name}"}`, `{"text": "b"}`},
+		{"array index out of range", `{"text": "${items.5.name}"}`, `{"text": null}`},
+		{"unknown key", `{"text": "${missing}"}`, `{"text": null}`},
+		{"unknown nested key", `{"text": "${usage.missing.value}"}`, `{"text": null}`},
+		{"lookup in a string", `{"text": "${title.length}"}`, `{"text": null}`},
+		{"unknown key within text", `{"text": "a${missing}b"}`, `{"text": "ab"}`},
+		{"whitespace", `{"text": "${ usage.national.value }"}`, `{"text": "12 min."}`},
+		{"negation", `{"a": "${!enabled}", "b": "${!missing}", "c": "${! empty}"}`, `{"a": false, "b": true, "c": true}`},
+		{"root", `{"$data": "${usage}", "text": "${$root.title} ${national.value}"}`, `{"text": "March 12 min."}`},
+		{"data of an object", `{"$data": "${usage.national}", "text": "${value}", "color": "${$data.color}"}`, `{"text": "12 min.", "color": "good"}`},
+		{"when true", `[{"$when": "${title}", "text": "a"}]`, `[{"text": "a"}]`},
+		{"when true number", `[{"$when": "${count}", "text": "a"}]`, `[{"text": "a"}]`},
+		{"when non-empty array", `[{"$when": "${alerts}", "text": "a"}]`, `[{"text": "a"}]`},
+		{"when false", `[{"$when": "${!enabled}", "text": "a"}, {"text": "b"}]`, `[{"text": "b"}]`},
+		{"when empty string", `[{"$when": "${empty}", "text": "a"}]`, `[]`},
+		{"when zero", `[{"$when": "${zero}", "text": "a"}]`, `[]`},
+		{"when null", `[{"$when": "${none}", "text": "a"}]`, `[]`},
+		{"when empty array", `[{"$when": "${list}", "text": "a"}]`, `[]`},
+		{"when missing", `[{"$when": "${missing}", "text": "a"}]`, `[]`},
+		{"when removes a property", `{"body": {"$when": "${missing}", "text": "a"}, "text": "b"}`, `{"text": "b"}`},
+		{"when is evaluated after data", `[{"$data": "${usage.national}", "$when": "${color}", "text": "${value}"}]`, `[{"text": "12 min."}]`},
+		{"data repeats the element", `[{"$data": "${items}", "text": "${name}: ${cost}"}]`, `[{"text": "a: 1"}, {"text": "b: 2"}]`},
+		{"data with index", `[{"$data": "${items}", "text": "${$index}. ${name}"}]`, `[{"text": "0. a"}, {"text": "1. b"}]`},
+		{"data of strings", `[{"$data": "${alerts}", "text": "* ${$data}"}]`, `[{"text": "* first"}, {"text": "* second"}]`},
+		{"data with root", `[{"$data": "${items}", "text": "${$root.title} ${name}"}]`, `[{"text": "March a"}, {"text": "March b"}]`},
+		{"data with when per item", `[{"$data": "${items}", "$when": "${!$index}", "text": "${name}"}]`, `[{"text": "a"}]`},
+		{"data of an empty array", `[{"text": "a"}, {"$data": "${list}", "text": "${name}"}]`, `[{"text": "a"}]`},
+		{"data between elements", `[{"text": "a"}, {"$data": "${items}", "text": "${name}"}, {"text": "c"}]`, `[{"text": "a"}, {"text": "a"}, {"text": "b"}, {"text": "c"}]`},
+		{"nested data", `{"rows": [{"$data": "${items}", "cells": [{"$data": "${$root.alerts}", "text": "${$data}"}], "name": "${name}"}]}`,
+			`{"rows": [{"cells": [{"text": "first"}, {"text": "second"}], "name": "a"}, {"cells": [{"text": "first"}, {"text": "second"}], "name": "b"}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := decode(t, data)
+			got, keep, err := expandTemplate(decode(t, tt.template), templateScope{d, d, 0})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !keep {
+				t.Fatal("template was removed")
+			}
+			if want := decode(t, tt.want); !reflect.DeepEqual(got, want) {
+				b, _ := json.Marshal(got)
+				t.Errorf("expandTemplate = %s, want %s", b, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandTemplateWhenFalse(t *testing.T) {
+	d := decode(t, `{}`)
+	got, keep, err := expandTemplate(decode(t, `{"$when": "${missing}", "text": "a"}`), templateScope{d, d, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keep || got != nil {
+		t.Errorf("expandTemplate = %v, %t, want nil, false", got, keep)
+	}
+}
+
+func TestExpandTemplateInvalid(t *testing.T) {
+	for _, template := range []string{
+		`{"text": "${a + b}"}`,
+		`{"text": "${items[0]}"}`,
+		`{"text": "${if(a, b, c)}"}`,
+		`{"text": "Hello ${'world'}"}`,
+		`{"text": "${}"}`,
+		`{"text": "${a..b}"}`,
+		`[{"$when": "${a == 1}"}]`,
+		`[{"$data": "${$parent}"}]`,
+	} {
+		d := decode(t, `{"a": 1}`)
+		if _, _, err := expandTemplate(decode(t, template), templateScope{d, d, 0}); err == nil {
+			t.Errorf("expandTemplate(%s) succeeded, want an error", template)
+		}
+	}
+}
+
+// sampleReports returns reports of every kind for rendering the card templates.
+func sampleReports() map[report.Kind]*report.Report {
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, easybell.Location)
+	end := start.AddDate(0, 1, 0)
+	tariff := report.Tariff{NationalQuota: 1000 * time.Minute, MobileQuota: 100 * time.Minute, NationalMinutePrice: 0.01, MobileMinutePrice: 0.09}
+	previous := &report.Report{
+		Kind:    report.KindPrevious,
+		Account: "Berlin",
+		Start:   start.AddDate(0, -1, 0),
+		End:     start,
+		Usage:   easybell.Usage{National: 850 * time.Minute, Mobile: 120 * time.Minute, Other: 3 * time.Minute},
+		Tariff:  tariff,
+	}
+	current := &report.Report{
+		Kind:    report.KindCurrent,
+		Account: "Hamburg",
+		Start:   start,
+		End:     end,
+		Usage:   easybell.Usage{National: 400 * time.Minute, Mobile: 92 * time.Minute},
+		Tariff:  tariff,
+		Forecast: &report.Forecast{
+			Model:           "linear",
+			Window:          35 * 24 * time.Hour,
+			Estimate:        easybell.Usage{National: 800 * time.Minute, Mobile: 140 * time.Minute},
+			Low:             easybell.Usage{National: 700 * time.Minute, Mobile: 120 * time.Minute},
+			High:            easybell.Usage{National: 900 * time.Minute, Mobile: 160 * time.Minute},
+			Level:           0.8,
+			MobileExhausted: start.AddDate(0, 0, 20),
+		},
+		Alerts: []report.Alert{{Kind: report.AlertMobile, Threshold: 90, Value: 92}},
+	}
+	summary := &report.Report{
+		Kind:     report.KindSummary,
+		Start:    start.AddDate(0, -1, 0),
+		End:      start,
+		Accounts: []*report.Report{previous, {Kind: report.KindPrevious, Account: "München", Start: previous.Start, End: previous.End, Tariff: tariff}},
+	}
+	return map[report.Kind]*report.Report{report.KindPrevious: previous, report.KindCurrent: current, report.KindSummary: summary}
+}
+
+func TestCardTemplates(t *testing.T) {
+	l := i18n.English
+	tests := []struct {
+		kind report.Kind
+		// want and notWant contain texts that must or must not be part of the card.
+		want    []string
+		notWant []string
+	}{
+		{
+			kind: report.KindPrevious,
+			want: []string{l.T("heading.previous"), "Berlin · September 2026", "850 min.", l.T("international_calls"), l.T("disclaimer")},
+		},
+		{
+			kind:    report.KindCurrent,
+			want:    []string{l.T("heading.current"), "Hamburg · October 2026", "🔔 Mobile usage has reached 90 % of the quota (92.00 %).", "800 min.", "700 – 900 min.", l.T("forecast")},
+			notWant: []string{l.T("international_calls")},
+		},
+		{
+			kind: report.KindSummary,
+			want: []string{"Berlin", "München", l.T("additional_total"), "€1.80"},
+		},
+	}
+	reports := sampleReports()
+	for _, tt := range tests {
+		t.Run(string(tt.kind), func(t *testing.T) {
+			var templates *CardTemplates
+			card, err := templates.Card(reports[tt.kind], l)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(card.Body) == 0 {
+				t.Fatal("card has no body")
+			}
+			if err = card.Validate(); err != nil {
+				t.Errorf("invalid card: %v", err)
+			}
+			b, err := json.Marshal(card)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := string(b)
+			for _, keyword := range []string{"${", "$data", "$when"} {
+				if strings.Contains(s, keyword) {
+					t.Errorf("card contains %q: %s", keyword, s)
+				}
+			}
+			for _, text := range tt.want {
+				if !strings.Contains(s, text) {
+					t.Errorf("card does not contain %q: %s", text, s)
+				}
+			}
+			for _, text := range tt.notWant {
+				if strings.Contains(s, text) {
+					t.Errorf("card contains %q: %s", text, s)
+				}
+			}
+		})
+	}
+}
+
+func TestCardTemplatesValidate(t *testing.T) {
+	if err := (&CardTemplates{}).Validate(); err != nil {
+		t.Errorf("embedded templates are invalid: %v", err)
+	}
+	dir := t.TempDir()
+	templates := &CardTemplates{Dir: dir}
+	if err := templates.Validate(); err != nil {
+		t.Errorf("empty directory is invalid: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "week.json"), []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := templates.Validate(); err == nil {
+		t.Error("invalid template in directory is accepted")
+	}
+	if err := (&CardTemplates{Dir: filepath.Join(dir, "missing")}).Validate(); err == nil {
+		t.Error("missing directory is accepted")
+	}
+}
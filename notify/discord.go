@@ -0,0 +1,114 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/lmr-hh/easybell-billing-info/i18n"
+	"github.com/lmr-hh/easybell-billing-info/report"
+)
+
+// Discord sends reports as embeds to a Discord webhook.
+type Discord struct {
+	client     *http.Client
+	webhookURL string
+	locale     *i18n.Locale
+}
+
+// NewDiscord creates a new notifier that sends reports to the Discord webhook at webhookURL.
+// The messages are written in the language of l.
+func NewDiscord(webhookURL string, l *i18n.Locale) *Discord {
+	return &Discord{http.DefaultClient, webhookURL, l}
+}
+
+// Notify sends r as an embed to the webhook of d.
+func (d *Discord) Notify(ctx context.Context, r *report.Report) error {
+	if err := postJSON(ctx, d.client, d.webhookURL, DiscordMessage(r, d.locale)); err != nil {
+		return fmt.Errorf("discord: %w", err)
+	}
+	return nil
+}
+
+// Render returns the message that d sends for r.
+func (d *Discord) Render(r *report.Report) (*Payload, error) {
+	return jsonPayload("discord", DiscordMessage(r, d.locale))
+}
+
+// discordMessage is the payload of a Discord webhook.
+type discordMessage struct {
+	Embeds []discordEmbed `json:"embeds"`
+}
+
+type discordEmbed struct {
+	Title       string              `json:"title,omitempty"`
+	Description string              `json:"description,omitempty"`
+	Color       int                 `json:"color"`
+	Fields      []discordEmbedField `json:"fields"`
+}
+
+type discordEmbedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+// Discord rejects embeds with more than 25 fields, messages with more than 10 embeds
+// and messages with more than 6000 characters in the titles, descriptions and fields of all embeds.
+const (
+	discordMaxFields = 25
+	discordMaxEmbeds = 10
+	discordMaxChars  = 6000
+)
+
+// DiscordMessage renders r as a Discord message.
+// The fields are split across several embeds if they do not fit into one.
+// The returned value can be encoded as JSON and sent to a webhook.
+func DiscordMessage(r *report.Report, l *i18n.Locale) any {
+	color := colorValue(slackColors[r.Status()])
+	embeds := []discordEmbed{{
+		Title:       heading(l, r),
+		Description: strings.Join(append(append([]string{"**" + title(l, r) + "**"}, alertLines(l, r)...), reportNotes(l, r)...), "\n\n"),
+		Color:       color,
+	}}
+	chars := utf8.RuneCountInString(embeds[0].Title) + utf8.RuneCountInString(embeds[0].Description)
+	for i, f := range discordFields(l, r, discordMaxChars-chars) {
+		if i > 0 && i%discordMaxFields == 0 {
+			embeds = append(embeds, discordEmbed{Color: color})
+		}
+		embed := &embeds[len(embeds)-1]
+		embed.Fields = append(embed.Fields, f)
+	}
+	return discordMessage{Embeds: embeds}
+}
+
+// discordFields returns the fields of r that fit into a message with at most chars characters.
+// If a summary has more accounts, the remaining accounts are replaced by a field with their number.
+// The total is always kept.
+func discordFields(l *i18n.Locale, r *report.Report, chars int) []discordEmbedField {
+	var fields []discordEmbedField
+	for _, f := range reportFields(l, r) {
+		fields = append(fields, discordEmbedField{f.Name, f.Value, f.Inline})
+	}
+	fits := func(fields []discordEmbedField) bool {
+		n := 0
+		for _, f := range fields {
+			n += utf8.RuneCountInString(f.Name) + utf8.RuneCountInString(f.Value)
+		}
+		return len(fields) <= discordMaxFields*discordMaxEmbeds && n <= chars
+	}
+	if len(fields) == 0 || fits(fields) {
+		return fields
+	}
+	total, accounts := fields[len(fields)-1], fields[:len(fields)-1]
+	for n := len(accounts) - 1; n > 0; n-- {
+		omitted := discordEmbedField{"…", l.T("more_accounts", len(accounts)-n), false}
+		if kept := append(slices.Clip(accounts[:n]), omitted, total); fits(kept) {
+			return kept
+		}
+	}
+	return []discordEmbedField{{"…", l.T("more_accounts", len(accounts)), false}, total}
+}
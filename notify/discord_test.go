@@ -0,0 +1,64 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/lmr-hh/easybell-billing-info/i18n"
+	"github.com/lmr-hh/easybell-billing-info/report"
+)
+
+// summaryOf returns a summary of n copies of the previous report of sampleReports with distinct account names.
+func summaryOf(n int) *report.Report {
+	reports := sampleReports()
+	previous, summary := reports[report.KindPrevious], *reports[report.KindSummary]
+	summary.Accounts = nil
+	for i := range n {
+		a := *previous
+		a.Account = fmt.Sprintf("Niederlassung %d %s", i+1, strings.Repeat("x", 20))
+		summary.Accounts = append(summary.Accounts, &a)
+	}
+	return &summary
+}
+
+func TestDiscordMessageLimits(t *testing.T) {
+	l := i18n.English
+	for _, n := range []int{1, 20, 60, 300} {
+		t.Run(fmt.Sprint(n), func(t *testing.T) {
+			r := summaryOf(n)
+			m := DiscordMessage(r, l).(discordMessage)
+			if len(m.Embeds) > discordMaxEmbeds {
+				t.Errorf("message has %d embeds, want at most %d", len(m.Embeds), discordMaxEmbeds)
+			}
+			var chars int
+			var fields []discordEmbedField
+			for _, e := range m.Embeds {
+				if len(e.Fields) > discordMaxFields {
+					t.Errorf("embed has %d fields, want at most %d", len(e.Fields), discordMaxFields)
+				}
+				chars += utf8.RuneCountInString(e.Title) + utf8.RuneCountInString(e.Description)
+				for _, f := range e.Fields {
+					chars += utf8.RuneCountInString(f.Name) + utf8.RuneCountInString(f.Value)
+				}
+				fields = append(fields, e.Fields...)
+			}
+			if chars > discordMaxChars {
+				t.Errorf("message has %d characters, want at most %d", chars, discordMaxChars)
+			}
+			if last := fields[len(fields)-1]; last.Name != l.T("additional_total") {
+				t.Errorf("last field is %q, want the total", last.Name)
+			}
+			kept := len(fields) - 1
+			if kept == n {
+				return
+			}
+			omitted := fields[len(fields)-2]
+			kept--
+			if want := l.T("more_accounts", n-kept); omitted.Value != want {
+				t.Errorf("omitted field is %q, want %q", omitted.Value, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,403 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	_ "embed"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lmr-hh/easybell-billing-info/i18n"
+	"github.com/lmr-hh/easybell-billing-info/report"
+)
+
+// These constants identify the supported connection security modes of an SMTP server.
+const (
+	// EmailSecuritySTARTTLS upgrades a plain connection using the STARTTLS command.
+	EmailSecuritySTARTTLS = "starttls"
+	// EmailSecurityTLS uses an implicit TLS connection.
+	EmailSecurityTLS = "tls"
+	// EmailSecurityNone does not encrypt the connection.
+	EmailSecurityNone = "none"
+)
+
+// EmailSecurityModes contains the supported connection security modes.
+var EmailSecurityModes = []string{EmailSecuritySTARTTLS, EmailSecurityTLS, EmailSecurityNone}
+
+// Email sends reports as multipart emails via SMTP.
+// The emails contain an HTML body that mirrors the Teams cards and a plain text alternative.
+type Email struct {
+	// Host and Port identify the SMTP server.
+	// If Port is 0, the default port of the Security mode is used.
+	Host string
+	Port int
+	// Security is one of the EmailSecurity constants.
+	// The zero value is equivalent to EmailSecuritySTARTTLS.
+	Security string
+	// Username and Password are used for authentication if Username is not empty.
+	Username string
+	Password string
+	From     string
+	To       []string
+	// AttachCalls attaches the calls of a report as CSV file if they are available.
+	AttachCalls bool
+	// Locale is the language of the emails.
+	// If it is nil, German is used.
+	Locale *i18n.Locale
+}
+
+// Notify sends r as an email to all recipients of e.
+func (e *Email) Notify(ctx context.Context, r *report.Report) error {
+	msg, err := e.Message(r, time.Now())
+	if err != nil {
+		return fmt.Errorf("email: %w", err)
+	}
+	if err = e.send(ctx, msg); err != nil {
+		return fmt.Errorf("email: %w", err)
+	}
+	return nil
+}
+
+// Render returns the HTML body of the email that e sends for r.
+// The headers, the plain text alternative and attachments are omitted so that the payload can be viewed in a browser.
+func (e *Email) Render(r *report.Report) (*Payload, error) {
+	var html bytes.Buffer
+	if err := emailTemplate.Execute(&html, newEmailView(r, e.Locale)); err != nil {
+		return nil, fmt.Errorf("email: %w", err)
+	}
+	return &Payload{Name: "email", Extension: ".html", Body: html.Bytes()}, nil
+}
+
+// Message renders r as a MIME message including all headers.
+func (e *Email) Message(r *report.Report, date time.Time) ([]byte, error) {
+	from, err := mail.ParseAddress(e.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender: %w", err)
+	}
+	to := make([]string, len(e.To))
+	for i, addr := range e.To {
+		a, err := mail.ParseAddress(addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recipient: %w", err)
+		}
+		to[i] = a.String()
+	}
+
+	var text, html bytes.Buffer
+	if err = report.WriteText(&text, r, e.Locale); err != nil {
+		return nil, err
+	}
+	if err = emailTemplate.Execute(&html, newEmailView(r, e.Locale)); err != nil {
+		return nil, err
+	}
+
+	var alternativeBody bytes.Buffer
+	alternative := multipart.NewWriter(&alternativeBody)
+	if err = writeQuotedPrintable(alternative, "text/plain; charset=utf-8", text.Bytes()); err != nil {
+		return nil, err
+	}
+	if err = writeQuotedPrintable(alternative, "text/html; charset=utf-8", html.Bytes()); err != nil {
+		return nil, err
+	}
+	if err = alternative.Close(); err != nil {
+		return nil, err
+	}
+
+	var body bytes.Buffer
+	mixed := multipart.NewWriter(&body)
+	part, err := mixed.CreatePart(textproto.MIMEHeader{"Content-Type": {"multipart/alternative; boundary=" + alternative.Boundary()}})
+	if err != nil {
+		return nil, err
+	}
+	if _, err = part.Write(alternativeBody.Bytes()); err != nil {
+		return nil, err
+	}
+	if e.AttachCalls && r.Calls != nil {
+		var calls bytes.Buffer
+		if err = report.WriteCallsCSV(&calls, r.Calls); err != nil {
+			return nil, err
+		}
+		part, err = mixed.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {"text/csv; charset=utf-8"},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachmentName(r)})},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err = writeBase64(part, calls.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+	if err = mixed.Close(); err != nil {
+		return nil, err
+	}
+
+	var msg bytes.Buffer
+	header := func(key, value string) {
+		msg.WriteString(key + ": " + value + "\r\n")
+	}
+	header("From", from.String())
+	header("To", strings.Join(to, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", fmt.Sprintf("%s · %s", heading(e.Locale, r), title(e.Locale, r))))
+	header("Date", date.Format(time.RFC1123Z))
+	header("Message-ID", messageID(from.Address))
+	header("MIME-Version", "1.0")
+	header("Content-Type", "multipart/mixed; boundary="+mixed.Boundary())
+	msg.WriteString("\r\n")
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
+
+// send delivers msg to the SMTP server of e.
+func (e *Email) send(ctx context.Context, msg []byte) error {
+	security := e.Security
+	if security == "" {
+		security = EmailSecuritySTARTTLS
+	}
+	port := e.Port
+	if port == 0 {
+		port = map[string]int{EmailSecuritySTARTTLS: 587, EmailSecurityTLS: 465, EmailSecurityNone: 25}[security]
+	}
+	addr := net.JoinHostPort(e.Host, strconv.Itoa(port))
+	tlsConfig := &tls.Config{ServerName: e.Host}
+
+	ctx, cancel := context.WithTimeout(ctx, SendTimeout)
+	defer cancel()
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	if security == EmailSecurityTLS {
+		conn = tls.Client(conn, tlsConfig)
+	}
+	c, err := smtp.NewClient(conn, e.Host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer func() {
+		_ = c.Close()
+	}()
+	if security == EmailSecuritySTARTTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return errors.New("server does not support STARTTLS")
+		}
+		if err = c.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+	if e.Username != "" {
+		if err = c.Auth(smtp.PlainAuth("", e.Username, e.Password, e.Host)); err != nil {
+			return err
+		}
+	}
+	from, err := mail.ParseAddress(e.From)
+	if err != nil {
+		return err
+	}
+	if err = c.Mail(from.Address); err != nil {
+		return err
+	}
+	for _, addr := range e.To {
+		to, err := mail.ParseAddress(addr)
+		if err != nil {
+			return err
+		}
+		if err = c.Rcpt(to.Address); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(msg); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// writeQuotedPrintable writes data with the contentType as quoted-printable part to w.
+func writeQuotedPrintable(w *multipart.Writer, contentType string, data []byte) error {
+	part, err := w.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return err
+	}
+	qp := quotedprintable.NewWriter(part)
+	if _, err = qp.Write(data); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+// writeBase64 writes data base64 encoded in lines of 76 characters to w.
+func writeBase64(w io.Writer, data []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 0 {
+		n := min(len(encoded), 76)
+		if _, err := io.WriteString(w, encoded[:n]+"\r\n"); err != nil {
+			return err
+		}
+		encoded = encoded[n:]
+	}
+	return nil
+}
+
+// attachmentName returns the file name of the CSV attachment of r.
+func attachmentName(r *report.Report) string {
+	name := "easybell-calls-" + r.Start.Format(time.DateOnly)
+	if r.Account != "" {
+		name += "-" + strings.ToLower(strings.Join(strings.Fields(r.Account), "-"))
+	}
+	return name + ".csv"
+}
+
+// messageID returns a new unique Message-ID for the domain of address.
+func messageID(address string) string {
+	domain := "localhost"
+	if i := strings.LastIndex(address, "@"); i >= 0 {
+		domain = address[i+1:]
+	}
+	return "<" + randomID() + "@" + domain + ">"
+}
+
+//go:embed email.html
+var emailHTML string
+
+// emailTemplate renders the HTML body of emails.
+var emailTemplate = template.Must(template.New("email").Parse(emailHTML))
+
+// htmlColors contains the CSS colors that correspond to the colors of the Teams cards.
+var htmlColors = map[report.Status]string{
+	report.StatusGood:      "#54a254",
+	report.StatusWarning:   "#c19c00",
+	report.StatusAttention: "#d13438",
+}
+
+// emailView is the data of the HTML email template.
+type emailView struct {
+	Heading string
+	Title   string
+	// Alerts describes the thresholds whose crossing triggered the report.
+	Alerts []string
+	// Usage contains the gauges of the usage in the period.
+	Usage []emailGauge
+	// Forecast contains the gauges of the estimated usage if r is a report of the current period.
+	Forecast       []emailGauge
+	ForecastTitle  string
+	ForecastText   string
+	ConfidenceText string
+	Exhaustion     []string
+	// Accounts contains the rows of a summary.
+	Accounts           []emailRow
+	Labels             cardLabels
+	CostLabel          string
+	Cost               string
+	InternationalCalls string
+	Disclaimer         string
+}
+
+// emailGauge is a single value of the usage.
+type emailGauge struct {
+	Label string
+	Value string
+	Range string
+	Color string
+}
+
+// emailRow is a row of the summary table.
+type emailRow struct {
+	Account  string
+	National emailGauge
+	Mobile   emailGauge
+	Other    emailGauge
+	Cost     string
+}
+
+// newEmailView returns the data of the HTML email for r in the language of l.
+func newEmailView(r *report.Report, l *i18n.Locale) emailView {
+	v := emailView{
+		Heading:   heading(l, r),
+		Title:     title(l, r),
+		Alerts:    alertTexts(l, r),
+		Labels:    cardLabels{l.T("account"), l.T("national"), l.T("mobile"), l.T("other"), l.T("cost")},
+		CostLabel: l.T("additional_cost"),
+		Cost:      formatCost(l, r.Cost()),
+	}
+	// color returns the CSS color for d. Values that are well below their quota use the neutral color if good is false.
+	color := func(d, quota time.Duration, good bool) string {
+		status := report.QuotaStatus(d, quota)
+		if status == report.StatusGood && !good {
+			return ""
+		}
+		return htmlColors[status]
+	}
+	switch r.Kind {
+	case report.KindSummary:
+		v.CostLabel = l.T("additional_total")
+		for _, a := range r.Accounts {
+			u := a.ExpectedUsage()
+			v.Accounts = append(v.Accounts, emailRow{
+				Account:  a.Account,
+				National: emailGauge{Value: formatMinutes(l, u.National), Color: color(u.National, a.Tariff.NationalQuota, false)},
+				Mobile:   emailGauge{Value: formatMinutes(l, u.Mobile), Color: color(u.Mobile, a.Tariff.MobileQuota, false)},
+				Other:    emailGauge{Value: formatMinutes(l, u.Other), Color: color(u.Other, 0, false)},
+				Cost:     formatCost(l, a.Cost()),
+			})
+		}
+	case report.KindCurrent:
+		f := r.Forecast
+		v.Usage = []emailGauge{
+			{Label: l.T("national"), Value: report.FormatDuration(r.Usage.National), Color: color(r.Usage.National, r.Tariff.NationalQuota, false)},
+			{Label: l.T("mobile"), Value: report.FormatDuration(r.Usage.Mobile), Color: color(r.Usage.Mobile, r.Tariff.MobileQuota, false)},
+			{Label: l.T("other"), Value: report.FormatDuration(r.Usage.Other), Color: color(r.Usage.Other, 0, false)},
+		}
+		v.Forecast = []emailGauge{
+			{Label: quotaLabel(l, "national", r.Tariff.NationalQuota), Value: formatMinutes(l, f.Estimate.National), Range: formatRange(l, f.Low.National, f.High.National), Color: color(f.Estimate.National, r.Tariff.NationalQuota, true)},
+			{Label: quotaLabel(l, "mobile", r.Tariff.MobileQuota), Value: formatMinutes(l, f.Estimate.Mobile), Range: formatRange(l, f.Low.Mobile, f.High.Mobile), Color: color(f.Estimate.Mobile, r.Tariff.MobileQuota, true)},
+			{Label: l.T("other"), Value: formatMinutes(l, f.Estimate.Other), Range: formatRange(l, f.Low.Other, f.High.Other), Color: color(f.Estimate.Other, 0, true)},
+		}
+		v.ForecastTitle = l.T("forecast")
+		v.ForecastText = forecastText(l, f)
+		v.ConfidenceText = confidenceText(l, f)
+		if exhaustion := exhaustionText(l, f); exhaustion != "" {
+			v.Exhaustion = strings.Split(exhaustion, "\n\n")
+		}
+	default:
+		v.Usage = []emailGauge{
+			{Label: quotaLabel(l, "national", r.Tariff.NationalQuota), Value: formatMinutes(l, r.Usage.National), Color: color(r.Usage.National, r.Tariff.NationalQuota, true)},
+			{Label: quotaLabel(l, "mobile", r.Tariff.MobileQuota), Value: formatMinutes(l, r.Usage.Mobile), Color: color(r.Usage.Mobile, r.Tariff.MobileQuota, true)},
+			{Label: l.T("other"), Value: formatMinutes(l, r.Usage.Other), Color: color(r.Usage.Other, 0, true)},
+		}
+		v.Disclaimer = l.T("disclaimer")
+	}
+	if r.Kind != report.KindSummary && r.Usage.Other > 0 {
+		v.InternationalCalls = l.T("international_calls")
+	}
+	return v
+}
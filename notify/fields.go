@@ -0,0 +1,114 @@
+package notify
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lmr-hh/easybell-billing-info/i18n"
+	"github.com/lmr-hh/easybell-billing-info/report"
+)
+
+// A field is a labeled value of a report.
+// It is used by services whose rich messages consist of a list of fields, such as Discord and Mattermost.
+type field struct {
+	Name  string
+	Value string
+	// Inline fields may be shown next to each other.
+	Inline bool
+}
+
+// reportFields returns the values of r as fields.
+func reportFields(l *i18n.Locale, r *report.Report) []field {
+	switch r.Kind {
+	case report.KindCurrent:
+		f := r.Forecast
+		return []field{
+			{l.T("national"), fieldDuration(r.Usage.National, r.Tariff.NationalQuota), true},
+			{l.T("mobile"), fieldDuration(r.Usage.Mobile, r.Tariff.MobileQuota), true},
+			{l.T("other"), fieldDuration(r.Usage.Other, 0), true},
+			{l.T("forecast") + " " + quotaLabel(l, "national", r.Tariff.NationalQuota), fieldForecast(l, f.Estimate.National, f.Low.National, f.High.National, r.Tariff.NationalQuota), true},
+			{l.T("forecast") + " " + quotaLabel(l, "mobile", r.Tariff.MobileQuota), fieldForecast(l, f.Estimate.Mobile, f.Low.Mobile, f.High.Mobile, r.Tariff.MobileQuota), true},
+			{l.T("forecast") + " " + l.T("other"), fieldForecast(l, f.Estimate.Other, f.Low.Other, f.High.Other, 0), true},
+			{l.T("additional_cost"), formatCost(l, r.Cost()), false},
+		}
+	case report.KindSummary:
+		var fields []field
+		for _, a := range r.Accounts {
+			fields = append(fields, field{a.Account, accountSummary(l, a, fieldMinutes), false})
+		}
+		return append(fields, field{l.T("additional_total"), formatCost(l, r.Cost()), false})
+	default:
+		return []field{
+			{quotaLabel(l, "national", r.Tariff.NationalQuota), fieldMinutes(l, r.Usage.National, r.Tariff.NationalQuota), true},
+			{quotaLabel(l, "mobile", r.Tariff.MobileQuota), fieldMinutes(l, r.Usage.Mobile, r.Tariff.MobileQuota), true},
+			{l.T("other"), fieldMinutes(l, r.Usage.Other, 0), true},
+			{l.T("additional_cost"), formatCost(l, r.Cost()), false},
+		}
+	}
+}
+
+// accountSummary describes the expected usage and the cost of an account of a summary in a single line.
+// The usage is formatted by minutes.
+func accountSummary(l *i18n.Locale, a *report.Report, minutes func(l *i18n.Locale, d, quota time.Duration) string) string {
+	u := a.ExpectedUsage()
+	return fmt.Sprintf("%s: %s · %s: %s · %s: %s · %s: %s",
+		l.T("national"), minutes(l, u.National, a.Tariff.NationalQuota),
+		l.T("mobile"), minutes(l, u.Mobile, a.Tariff.MobileQuota),
+		l.T("other"), minutes(l, u.Other, 0),
+		l.T("cost"), formatCost(l, a.Cost()),
+	)
+}
+
+// reportNotes returns the explanations and warnings that accompany the fields of r.
+func reportNotes(l *i18n.Locale, r *report.Report) []string {
+	var notes []string
+	switch r.Kind {
+	case report.KindCurrent:
+		notes = append(notes, forecastText(l, r.Forecast)+" "+confidenceText(l, r.Forecast))
+		if exhaustion := exhaustionText(l, r.Forecast); exhaustion != "" {
+			notes = append(notes, strings.Split(exhaustion, "\n\n")...)
+		}
+	case report.KindPrevious:
+		notes = append(notes, l.T("disclaimer"))
+	}
+	if r.Kind != report.KindSummary && r.Usage.Other > 0 {
+		notes = append(notes, l.T("international_calls"))
+	}
+	return notes
+}
+
+// statusMarker returns an emoji that marks d if it is near or above its quota.
+// It uses the same thresholds as the colors of the Teams cards.
+func statusMarker(d, quota time.Duration) string {
+	switch report.QuotaStatus(d, quota) {
+	case report.StatusWarning:
+		return "🟡 "
+	case report.StatusAttention:
+		return "🔴 "
+	default:
+		return ""
+	}
+}
+
+// fieldMinutes formats d in minutes marked by its status.
+func fieldMinutes(l *i18n.Locale, d, quota time.Duration) string {
+	return statusMarker(d, quota) + formatMinutes(l, d)
+}
+
+// fieldDuration formats d as mm:ss marked by its status.
+func fieldDuration(d, quota time.Duration) string {
+	return statusMarker(d, quota) + report.FormatDuration(d)
+}
+
+// fieldForecast formats an estimate in minutes marked by its status together with its range.
+func fieldForecast(l *i18n.Locale, estimate, low, high, quota time.Duration) string {
+	return fmt.Sprintf("%s\n(%s)", fieldMinutes(l, estimate, quota), formatRange(l, low, high))
+}
+
+// colorValue returns the numeric value of a color in hexadecimal #RRGGBB notation.
+func colorValue(color string) int {
+	v, _ := strconv.ParseInt(strings.TrimPrefix(color, "#"), 16, 32)
+	return int(v)
+}
@@ -0,0 +1,80 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/lmr-hh/easybell-billing-info/i18n"
+	"github.com/lmr-hh/easybell-billing-info/report"
+)
+
+// Gotify sends short alerts to a Gotify server.
+// The priority of an alert depends on the status of the quotas of the report,
+// the alert links to the easyBell portal for details.
+type Gotify struct {
+	// URL is the base URL of the Gotify server.
+	URL string
+	// Token is the token of the Gotify application that sends the alerts.
+	Token string
+	// Click is the URL that is opened when the alert is tapped.
+	// If Click is empty, the easyBell portal is opened.
+	Click string
+	// MinStatus is the least status of the quotas of reports that are sent.
+	MinStatus report.Status
+	// Locale is the language of the alerts.
+	// If it is nil, German is used.
+	Locale *i18n.Locale
+}
+
+// Notify sends an alert about r to the server of g if the status of the quotas of r is at least g.MinStatus.
+func (g *Gotify) Notify(ctx context.Context, r *report.Report) error {
+	if pushStatus(r) < g.MinStatus {
+		return nil
+	}
+	header := http.Header{
+		"Content-Type": {"application/json"},
+		"X-Gotify-Key": {g.Token},
+	}
+	body, err := json.Marshal(g.Message(r))
+	if err == nil {
+		err = post(ctx, http.DefaultClient, strings.TrimRight(g.URL, "/")+"/message", header, body)
+	}
+	if err != nil {
+		return fmt.Errorf("gotify: %w", err)
+	}
+	return nil
+}
+
+// Render returns the message that g sends for r.
+// The payload is nil if the status of the quotas of r is below g.MinStatus.
+func (g *Gotify) Render(r *report.Report) (*Payload, error) {
+	if pushStatus(r) < g.MinStatus {
+		return nil, nil
+	}
+	return jsonPayload("gotify", g.Message(r))
+}
+
+// gotifyMessage is a message of the Gotify API.
+type gotifyMessage struct {
+	Title    string         `json:"title"`
+	Message  string         `json:"message"`
+	Priority int            `json:"priority"`
+	Extras   map[string]any `json:"extras"`
+}
+
+// Message renders an alert about r as a Gotify message.
+// The returned value can be encoded as JSON and sent to the server of g.
+func (g *Gotify) Message(r *report.Report) any {
+	return gotifyMessage{
+		Title:    alertTitle(g.Locale, r),
+		Message:  alertText(g.Locale, r),
+		Priority: gotifyPriorities[pushStatus(r)],
+		Extras: map[string]any{
+			"client::display":      map[string]any{"contentType": "text/plain"},
+			"client::notification": map[string]any{"click": map[string]any{"url": clickURL(g.Click)}},
+		},
+	}
+}
@@ -0,0 +1,84 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// SendTimeout is the maximum duration of a single request to a notification service.
+const SendTimeout = 10 * time.Second
+
+// errInvalidURL is returned if a request cannot be created for a URL.
+var errInvalidURL = errors.New("invalid URL")
+
+// statusError is returned if a notification service responds with an unsuccessful status code.
+type statusError struct {
+	code   int
+	status string
+	body   []byte
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("unexpected response status %s: %s", e.status, e.body)
+}
+
+// post sends body with the specified headers to target.
+// Webhook URLs are secrets, so the returned errors do not contain target.
+func post(ctx context.Context, client *http.Client, target string, header http.Header, body []byte) error {
+	return send(ctx, client, http.MethodPost, target, header, body)
+}
+
+// send sends body with the specified method and headers to target.
+// The returned errors do not contain target.
+func send(ctx context.Context, client *http.Client, method, target string, header http.Header, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, SendTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return errInvalidURL
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return fmt.Errorf("request failed: %w", urlErr.Err)
+		}
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &statusError{resp.StatusCode, resp.Status, bytes.TrimSpace(msg)}
+	}
+	return nil
+}
+
+// postJSON sends payload encoded as JSON to target.
+func postJSON(ctx context.Context, client *http.Client, target string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return post(ctx, client, target, http.Header{"Content-Type": {"application/json"}}, body)
+}
+
+// randomID returns a new random hexadecimal ID.
+func randomID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,87 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/lmr-hh/easybell-billing-info/i18n"
+	"github.com/lmr-hh/easybell-billing-info/report"
+)
+
+// Matrix sends reports as formatted notices to a Matrix room using the client-server API.
+type Matrix struct {
+	// Homeserver is the base URL of the homeserver, e.g. https://matrix.example.com.
+	Homeserver string
+	// AccessToken authenticates a user that has joined the room.
+	AccessToken string
+	// RoomID is the ID of the room, e.g. !abc:example.com.
+	RoomID string
+	// Locale is the language of the messages.
+	// If it is nil, German is used.
+	Locale *i18n.Locale
+}
+
+// Notify sends r as a message to the room of m.
+func (m *Matrix) Notify(ctx context.Context, r *report.Report) error {
+	body, err := json.Marshal(MatrixMessage(r, m.Locale))
+	if err != nil {
+		return fmt.Errorf("matrix: %w", err)
+	}
+	target := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+		strings.TrimRight(m.Homeserver, "/"), url.PathEscape(m.RoomID), randomID())
+	header := http.Header{
+		"Authorization": {"Bearer " + m.AccessToken},
+		"Content-Type":  {"application/json"},
+	}
+	if err = send(ctx, http.DefaultClient, http.MethodPut, target, header, body); err != nil {
+		return fmt.Errorf("matrix: %w", err)
+	}
+	return nil
+}
+
+// Render returns the content of the message that m sends for r.
+func (m *Matrix) Render(r *report.Report) (*Payload, error) {
+	return jsonPayload("matrix", MatrixMessage(r, m.Locale))
+}
+
+// matrixMessage is the content of an m.room.message event with an HTML body.
+type matrixMessage struct {
+	MsgType       string `json:"msgtype"`
+	Body          string `json:"body"`
+	Format        string `json:"format"`
+	FormattedBody string `json:"formatted_body"`
+}
+
+// MatrixMessage renders r as the content of a Matrix message in the language of l.
+// Clients that do not support HTML show the plain text body.
+func MatrixMessage(r *report.Report, l *i18n.Locale) any {
+	var text, formatted strings.Builder
+	fmt.Fprintf(&text, "%s\n%s\n", heading(l, r), title(l, r))
+	fmt.Fprintf(&formatted, "<h3>%s</h3><p><em>%s</em></p>", html.EscapeString(heading(l, r)), html.EscapeString(title(l, r)))
+	for _, a := range alertTexts(l, r) {
+		fmt.Fprintf(&text, "🔔 %s\n", a)
+		fmt.Fprintf(&formatted, "<p><strong>🔔 %s</strong></p>", html.EscapeString(a))
+	}
+	formatted.WriteString("<ul>")
+	for _, f := range reportFields(l, r) {
+		value := strings.ReplaceAll(f.Value, "\n", " ")
+		fmt.Fprintf(&text, "\n%s: %s", f.Name, value)
+		fmt.Fprintf(&formatted, "<li><strong>%s:</strong> %s</li>", html.EscapeString(f.Name), html.EscapeString(value))
+	}
+	formatted.WriteString("</ul>")
+	for _, note := range reportNotes(l, r) {
+		fmt.Fprintf(&text, "\n\n%s", note)
+		fmt.Fprintf(&formatted, "<p><small>%s</small></p>", html.EscapeString(note))
+	}
+	return matrixMessage{
+		MsgType:       "m.notice",
+		Body:          text.String(),
+		Format:        "org.matrix.custom.html",
+		FormattedBody: formatted.String(),
+	}
+}
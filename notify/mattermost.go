@@ -0,0 +1,75 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/lmr-hh/easybell-billing-info/i18n"
+	"github.com/lmr-hh/easybell-billing-info/report"
+)
+
+// Mattermost sends reports as message attachments to a Mattermost incoming webhook.
+type Mattermost struct {
+	client     *http.Client
+	webhookURL string
+	locale     *i18n.Locale
+}
+
+// NewMattermost creates a new notifier that sends reports to the Mattermost incoming webhook at webhookURL.
+// The messages are written in the language of l.
+func NewMattermost(webhookURL string, l *i18n.Locale) *Mattermost {
+	return &Mattermost{http.DefaultClient, webhookURL, l}
+}
+
+// Notify sends r as a message attachment to the webhook of m.
+func (m *Mattermost) Notify(ctx context.Context, r *report.Report) error {
+	if err := postJSON(ctx, m.client, m.webhookURL, MattermostMessage(r, m.locale)); err != nil {
+		return fmt.Errorf("mattermost: %w", err)
+	}
+	return nil
+}
+
+// Render returns the message that m sends for r.
+func (m *Mattermost) Render(r *report.Report) (*Payload, error) {
+	return jsonPayload("mattermost", MattermostMessage(r, m.locale))
+}
+
+// mattermostMessage is the payload of a Mattermost incoming webhook.
+type mattermostMessage struct {
+	Attachments []mattermostAttachment `json:"attachments"`
+}
+
+type mattermostAttachment struct {
+	Fallback string            `json:"fallback"`
+	Color    string            `json:"color"`
+	Title    string            `json:"title"`
+	Text     string            `json:"text"`
+	Fields   []mattermostField `json:"fields"`
+	Footer   string            `json:"footer,omitempty"`
+}
+
+type mattermostField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+// MattermostMessage renders r as a Mattermost message attachment.
+// The returned value can be encoded as JSON and sent to an incoming webhook.
+func MattermostMessage(r *report.Report, l *i18n.Locale) any {
+	a := mattermostAttachment{
+		Fallback: fmt.Sprintf("%s · %s", heading(l, r), title(l, r)),
+		Color:    slackColors[r.Status()],
+		Title:    heading(l, r),
+		Text:     strings.Join(append([]string{"**" + title(l, r) + "**"}, alertLines(l, r)...), "\n\n"),
+	}
+	for _, f := range reportFields(l, r) {
+		a.Fields = append(a.Fields, mattermostField{f.Name, f.Value, f.Inline})
+	}
+	if notes := reportNotes(l, r); len(notes) > 0 {
+		a.Footer = strings.Join(notes, " ")
+	}
+	return mattermostMessage{Attachments: []mattermostAttachment{a}}
+}
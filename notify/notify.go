@@ -0,0 +1,32 @@
+// Package notify delivers usage reports to chat services and other destinations.
+//
+// Every destination implements the [Notifier] interface and renders a [report.Report] in its own format.
+// The payloads can also be rendered without sending them using the [Renderer] interface, e.g. for a [DryRun].
+package notify
+
+import (
+	"context"
+	"errors"
+
+	"github.com/lmr-hh/easybell-billing-info/report"
+)
+
+// A Notifier sends reports to a destination.
+type Notifier interface {
+	// Notify sends r to the destination of the notifier.
+	Notify(ctx context.Context, r *report.Report) error
+}
+
+// Multi is a Notifier that sends reports to several notifiers.
+type Multi []Notifier
+
+// Notify sends r to all notifiers in m.
+// A failing notifier does not prevent r from being sent to the other notifiers.
+// The errors of all notifiers are returned together.
+func (m Multi) Notify(ctx context.Context, r *report.Report) error {
+	var errs []error
+	for _, n := range m {
+		errs = append(errs, n.Notify(ctx, r))
+	}
+	return errors.Join(errs...)
+}
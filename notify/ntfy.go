@@ -0,0 +1,98 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/lmr-hh/easybell-billing-info/i18n"
+	"github.com/lmr-hh/easybell-billing-info/report"
+)
+
+// DefaultNtfyServer is the public ntfy server.
+const DefaultNtfyServer = "https://ntfy.sh"
+
+// Ntfy sends short alerts to an ntfy topic.
+// The priority and the tags of an alert depend on the status of the quotas of the report,
+// the alert links to the easyBell portal for details.
+type Ntfy struct {
+	// Server is the base URL of the ntfy server.
+	// If Server is empty, DefaultNtfyServer is used.
+	Server string
+	Topic  string
+	// Token is an access token for protected topics.
+	Token string
+	// Click is the URL that is opened when the alert is tapped.
+	// If Click is empty, the easyBell portal is opened.
+	Click string
+	// MinStatus is the least status of the quotas of reports that are sent.
+	MinStatus report.Status
+	// Locale is the language of the alerts.
+	// If it is nil, German is used.
+	Locale *i18n.Locale
+}
+
+// Notify sends an alert about r to the topic of n if the status of the quotas of r is at least n.MinStatus.
+func (n *Ntfy) Notify(ctx context.Context, r *report.Report) error {
+	if pushStatus(r) < n.MinStatus {
+		return nil
+	}
+	server := n.Server
+	if server == "" {
+		server = DefaultNtfyServer
+	}
+	header := http.Header{"Content-Type": {"application/json"}}
+	if n.Token != "" {
+		header.Set("Authorization", "Bearer "+n.Token)
+	}
+	body, err := json.Marshal(n.Message(r))
+	if err == nil {
+		err = post(ctx, http.DefaultClient, strings.TrimRight(server, "/")+"/", header, body)
+	}
+	if err != nil {
+		return fmt.Errorf("ntfy: %w", err)
+	}
+	return nil
+}
+
+// Render returns the message that n publishes for r.
+// The payload is nil if the status of the quotas of r is below n.MinStatus.
+func (n *Ntfy) Render(r *report.Report) (*Payload, error) {
+	if pushStatus(r) < n.MinStatus {
+		return nil, nil
+	}
+	return jsonPayload("ntfy", n.Message(r))
+}
+
+// ntfyMessage is a message that is published as JSON.
+type ntfyMessage struct {
+	Topic    string   `json:"topic"`
+	Title    string   `json:"title"`
+	Message  string   `json:"message"`
+	Priority int      `json:"priority"`
+	Tags     []string `json:"tags"`
+	Click    string   `json:"click"`
+}
+
+// Message renders an alert about r as an ntfy message.
+// The returned value can be encoded as JSON and published to the server of n.
+func (n *Ntfy) Message(r *report.Report) any {
+	status := pushStatus(r)
+	tags := ntfyTags[status]
+	if international(r) {
+		tags = append(tags[:len(tags):len(tags)], ntfyInternationalTag)
+	}
+	if r.Account != "" {
+		tags = append(tags[:len(tags):len(tags)], r.Account)
+	}
+	return ntfyMessage{
+		Topic:    n.Topic,
+		Title:    alertTitle(n.Locale, r),
+		Message:  alertText(n.Locale, r),
+		Priority: ntfyPriorities[status],
+		Tags:     tags,
+		Click:    clickURL(n.Click),
+	}
+}
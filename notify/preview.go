@@ -0,0 +1,170 @@
+package notify
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/atc0005/go-teams-notify/v2/adaptivecard"
+
+	"github.com/lmr-hh/easybell-billing-info/i18n"
+	"github.com/lmr-hh/easybell-billing-info/report"
+)
+
+//go:embed preview.html
+var previewHTML string
+
+// previewTemplate renders the preview page of cards.
+var previewTemplate = template.Must(template.New("preview").Parse(previewHTML))
+
+// These are the values of the Adaptive Card properties that are supported by the preview.
+// Other values are ignored and rendered like the default value.
+var (
+	previewSizes      = []string{"small", "default", "medium", "large", "extralarge"}
+	previewWeights    = []string{"lighter", "default", "bolder"}
+	previewColors     = []string{"default", "dark", "light", "accent", "good", "warning", "attention"}
+	previewAlignments = []string{"left", "center", "right"}
+	previewSpacings   = []string{"none", "small", "default", "medium", "large", "extralarge", "padding"}
+)
+
+// previewPixels matches explicit column widths in pixels.
+var previewPixels = regexp.MustCompile(`^[0-9]+px$`)
+
+// previewView is the data of the preview template.
+type previewView struct {
+	Title    string
+	Messages []previewMessage
+}
+
+// previewMessage is a message with a single card.
+type previewMessage struct {
+	// Account is the name of the account of the report.
+	// It is empty for summaries and if only a single account is configured.
+	Account string
+	Body    []previewElement
+}
+
+// previewElement is an element of a card with its properties translated to CSS classes.
+type previewElement struct {
+	Type    string
+	Class   string
+	Text    string
+	Items   []previewElement
+	Columns []previewColumn
+	Facts   []adaptivecard.Fact
+}
+
+// previewColumn is a column of a column set.
+type previewColumn struct {
+	Style template.CSS
+	Items []previewElement
+}
+
+// Preview writes an HTML page to w that shows the cards of reports in the language of l.
+// The page approximates the look of the cards in Microsoft Teams so that templates can be reviewed without sending them.
+// Only the elements and properties used by the embedded templates are supported:
+// TextBlock, Container, ColumnSet, Column and FactSet.
+func (t *CardTemplates) Preview(w io.Writer, reports []*report.Report, l *i18n.Locale) error {
+	v := previewView{Title: l.T("preview.title")}
+	for _, r := range reports {
+		card, err := t.Card(r, l)
+		if err != nil {
+			return err
+		}
+		v.Messages = append(v.Messages, previewMessage{Account: r.Account, Body: previewElements(card.Body)})
+	}
+	return previewTemplate.Execute(w, v)
+}
+
+// previewElements translates the visible elements of a card.
+func previewElements(elements []adaptivecard.Element) []previewElement {
+	var result []previewElement
+	for i := range elements {
+		if e := &elements[i]; e.Visible == nil || *e.Visible {
+			result = append(result, previewElementOf(e))
+		}
+	}
+	return result
+}
+
+// previewElementOf translates a single element.
+// Unsupported elements are rendered as a placeholder with the type of the element.
+func previewElementOf(e *adaptivecard.Element) previewElement {
+	classes := []string{"element"}
+	property := func(prefix, value string, supported []string) {
+		if value = strings.ToLower(value); slices.Contains(supported, value) {
+			classes = append(classes, prefix+value)
+		}
+	}
+	property("spacing-", e.Spacing, previewSpacings)
+	if e.Separator {
+		classes = append(classes, "separator")
+	}
+
+	p := previewElement{Type: e.Type}
+	switch e.Type {
+	case adaptivecard.TypeElementTextBlock:
+		classes = append(classes, "text-block")
+		property("size-", e.Size, previewSizes)
+		property("weight-", e.Weight, previewWeights)
+		property("color-", e.Color, previewColors)
+		property("align-", e.HorizontalAlignment, previewAlignments)
+		if e.IsSubtle {
+			classes = append(classes, "subtle")
+		}
+		if e.Wrap {
+			classes = append(classes, "wrap")
+		}
+		p.Text = e.Text
+	case adaptivecard.TypeElementContainer:
+		classes = append(classes, "container")
+		p.Items = previewElements(e.Items)
+	case adaptivecard.TypeElementColumnSet:
+		classes = append(classes, "column-set")
+		for _, c := range e.Columns {
+			var items []adaptivecard.Element
+			for _, item := range c.Items {
+				if item != nil {
+					items = append(items, *item)
+				}
+			}
+			p.Columns = append(p.Columns, previewColumn{Style: previewColumnStyle(c.Width), Items: previewElements(items)})
+		}
+	case adaptivecard.TypeElementFactSet:
+		classes = append(classes, "fact-set")
+		p.Facts = e.Facts
+	default:
+		classes = append(classes, "unsupported")
+		p.Type = "unsupported"
+		p.Text = e.Type
+	}
+	p.Class = strings.Join(classes, " ")
+	return p
+}
+
+// previewColumnStyle returns the CSS flex properties that correspond to the width of a column.
+// Widths are "auto", "stretch", a relative weight or an explicit width in pixels.
+func previewColumnStyle(width any) template.CSS {
+	switch w := width.(type) {
+	case float64:
+		if w > 0 {
+			return template.CSS(fmt.Sprintf("flex: %g 1 0", w))
+		}
+	case string:
+		if w == "auto" {
+			return "flex: 0 0 auto"
+		}
+		if previewPixels.MatchString(w) {
+			return template.CSS("flex: 0 0 " + w)
+		}
+		if weight, err := strconv.ParseFloat(w, 64); err == nil && weight > 0 {
+			return template.CSS(fmt.Sprintf("flex: %g 1 0", weight))
+		}
+	}
+	return "flex: 1 1 0"
+}
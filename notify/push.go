@@ -0,0 +1,67 @@
+package notify
+
+import (
+	"slices"
+
+	"github.com/lmr-hh/easybell-billing-info/easybell"
+	"github.com/lmr-hh/easybell-billing-info/report"
+)
+
+// clickURL returns url or the URL of the easyBell portal if url is empty.
+func clickURL(url string) string {
+	if url == "" {
+		return easybell.PortalURL
+	}
+	return url
+}
+
+// pushStatus returns the status of r that determines whether and how urgently an alert is pushed.
+// Unlike [report.Report.Status] it only considers the national and mobile quotas.
+// International usage is not included in any quota, so it is only mentioned in the alert.
+func pushStatus(r *report.Report) report.Status {
+	if r.Kind == report.KindSummary {
+		status := report.StatusGood
+		for _, a := range r.Accounts {
+			status = max(status, pushStatus(a))
+		}
+		return status
+	}
+	u := r.ExpectedUsage()
+	return max(
+		report.QuotaStatus(u.National, r.Tariff.NationalQuota),
+		report.QuotaStatus(u.Mobile, r.Tariff.MobileQuota),
+	)
+}
+
+// international reports whether international usage is expected in r or in any account of a summary.
+func international(r *report.Report) bool {
+	if r.Kind == report.KindSummary {
+		return slices.ContainsFunc(r.Accounts, international)
+	}
+	return r.ExpectedUsage().Other > 0
+}
+
+// ntfyPriorities and gotifyPriorities map the push status of a report to a priority of the respective service.
+var (
+	ntfyPriorities = map[report.Status]int{
+		report.StatusGood:      3,
+		report.StatusWarning:   4,
+		report.StatusAttention: 5,
+	}
+	gotifyPriorities = map[report.Status]int{
+		report.StatusGood:      2,
+		report.StatusWarning:   5,
+		report.StatusAttention: 8,
+	}
+)
+
+// ntfyTags contains the tags of an alert depending on the push status of the report.
+// ntfy shows tags that are emoji short codes as emojis.
+var ntfyTags = map[report.Status][]string{
+	report.StatusGood:      {"telephone_receiver", "white_check_mark"},
+	report.StatusWarning:   {"telephone_receiver", "warning"},
+	report.StatusAttention: {"telephone_receiver", "rotating_light"},
+}
+
+// ntfyInternationalTag is added to the tags of an alert about a report with international usage.
+const ntfyInternationalTag = "globe_with_meridians"
@@ -0,0 +1,90 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lmr-hh/easybell-billing-info/report"
+)
+
+// A Payload is a report rendered in the format in which a notifier sends it.
+type Payload struct {
+	// Name identifies the notifier, e.g. "teams".
+	Name string
+	// Extension is the file extension of the format of Body including the dot, e.g. ".json".
+	Extension string
+	Body      []byte
+}
+
+// A Renderer renders reports without sending them.
+// All notifiers of this package except [Multi] implement Renderer.
+type Renderer interface {
+	// Render returns the payload that would be sent for r.
+	// If r would not be sent, e.g. because its status is below a minimum status, the payload is nil.
+	Render(r *report.Report) (*Payload, error)
+}
+
+// DryRun is a Notifier that writes the payloads of a renderer instead of sending them.
+// JSON payloads are indented for readability.
+type DryRun struct {
+	Renderer Renderer
+	// Dir is the directory in which the payloads are stored as files.
+	// If Dir is empty, the payloads are written to Out, each preceded by a line with its file name.
+	Dir string
+	Out io.Writer
+}
+
+// Notify renders r and writes the payload to the directory or writer of d.
+func (d *DryRun) Notify(_ context.Context, r *report.Report) error {
+	p, err := d.Renderer.Render(r)
+	if err != nil || p == nil {
+		return err
+	}
+	body := p.Body
+	if p.Extension == ".json" {
+		var indented bytes.Buffer
+		if json.Indent(&indented, body, "", "  ") == nil {
+			body = indented.Bytes()
+		}
+	}
+	if !bytes.HasSuffix(body, []byte("\n")) {
+		body = append(body[:len(body):len(body)], '\n')
+	}
+	name := payloadName(r, p)
+	if d.Dir == "" {
+		_, err = fmt.Fprintf(d.Out, "==> %s <==\n%s\n", name, body)
+	} else {
+		err = os.WriteFile(filepath.Join(d.Dir, name), body, 0o644)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", p.Name, err)
+	}
+	return nil
+}
+
+// payloadName returns the file name of the payload p of r.
+// Path separators in the name of the account are replaced so that the file is created in the target directory.
+func payloadName(r *report.Report, p *Payload) string {
+	name := fmt.Sprintf("easybell-%s-%s", r.Kind, r.Start.Format(time.DateOnly))
+	if r.Account != "" {
+		account := strings.ToLower(strings.Join(strings.Fields(r.Account), "-"))
+		name += "-" + strings.NewReplacer("/", "-", `\`, "-").Replace(account)
+	}
+	return name + "-" + p.Name + p.Extension
+}
+
+// jsonPayload returns a payload of the notifier name that contains v encoded as JSON.
+func jsonPayload(name string, v any) (*Payload, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return &Payload{Name: name, Extension: ".json", Body: body}, nil
+}
@@ -0,0 +1,208 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/lmr-hh/easybell-billing-info/i18n"
+	"github.com/lmr-hh/easybell-billing-info/report"
+)
+
+// Slack sends reports as Block Kit messages to a Slack incoming webhook.
+type Slack struct {
+	client     *http.Client
+	webhookURL string
+	locale     *i18n.Locale
+}
+
+// NewSlack creates a new notifier that sends reports to the Slack incoming webhook at webhookURL.
+// The messages are written in the language of l.
+func NewSlack(webhookURL string, l *i18n.Locale) *Slack {
+	return &Slack{http.DefaultClient, webhookURL, l}
+}
+
+// Notify sends r as a Block Kit message to the webhook of s.
+func (s *Slack) Notify(ctx context.Context, r *report.Report) error {
+	if err := postJSON(ctx, s.client, s.webhookURL, SlackMessage(r, s.locale)); err != nil {
+		return fmt.Errorf("slack: %w", err)
+	}
+	return nil
+}
+
+// Render returns the Block Kit message that s sends for r.
+func (s *Slack) Render(r *report.Report) (*Payload, error) {
+	return jsonPayload("slack", SlackMessage(r, s.locale))
+}
+
+// These are the colors of the attachment bar of a Slack message, depending on the status of the report.
+var slackColors = map[report.Status]string{
+	report.StatusGood:      "#2EB886",
+	report.StatusWarning:   "#DAA038",
+	report.StatusAttention: "#A30200",
+}
+
+// slackMessage is a Slack message whose blocks are wrapped in an attachment so that it is shown with a colored bar.
+type slackMessage struct {
+	Text        string            `json:"text"`
+	Attachments []slackAttachment `json:"attachments"`
+}
+
+type slackAttachment struct {
+	Color  string       `json:"color"`
+	Blocks []slackBlock `json:"blocks"`
+}
+
+// slackBlock is a Block Kit layout block.
+// Only the fields required by header, section, context and divider blocks are supported.
+type slackBlock struct {
+	Type     string      `json:"type"`
+	Text     *slackText  `json:"text,omitempty"`
+	Fields   []slackText `json:"fields,omitempty"`
+	Elements []slackText `json:"elements,omitempty"`
+}
+
+// slackText is a Block Kit text object.
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// SlackMessage renders r as a Slack message using Block Kit.
+// The returned value can be encoded as JSON and sent to an incoming webhook.
+func SlackMessage(r *report.Report, l *i18n.Locale) any {
+	blocks := []slackBlock{
+		{Type: "header", Text: &slackText{"plain_text", heading(l, r)}},
+		slackContext(title(l, r)),
+	}
+	for _, text := range alertTexts(l, r) {
+		blocks = append(blocks, slackSection(":bell: *"+text+"*"))
+	}
+	blocks = append(blocks, slackBlock{Type: "divider"})
+	switch r.Kind {
+	case report.KindCurrent:
+		blocks = append(blocks, currentSlackBlocks(l, r)...)
+	case report.KindSummary:
+		blocks = append(blocks, summarySlackBlocks(l, r)...)
+	default:
+		blocks = append(blocks, previousSlackBlocks(l, r)...)
+	}
+	return slackMessage{
+		Text: fmt.Sprintf("%s · %s", heading(l, r), title(l, r)),
+		Attachments: []slackAttachment{{
+			Color:  slackColors[r.Status()],
+			Blocks: blocks,
+		}},
+	}
+}
+
+// previousSlackBlocks returns the blocks of a report of a completed billing period.
+func previousSlackBlocks(l *i18n.Locale, r *report.Report) []slackBlock {
+	blocks := []slackBlock{
+		slackFields(
+			fmt.Sprintf("*%s*\n%s", quotaLabel(l, "national", r.Tariff.NationalQuota), slackMinutes(l, r.Usage.National, r.Tariff.NationalQuota, slackEmojiGood)),
+			fmt.Sprintf("*%s*\n%s", quotaLabel(l, "mobile", r.Tariff.MobileQuota), slackMinutes(l, r.Usage.Mobile, r.Tariff.MobileQuota, slackEmojiGood)),
+			fmt.Sprintf("*%s*\n%s", l.T("other"), slackMinutes(l, r.Usage.Other, 0, slackEmojiGood)),
+		),
+		slackSection(fmt.Sprintf("*%s:* %s", l.T("additional_cost"), formatCost(l, r.Cost()))),
+	}
+	if r.Usage.Other > 0 {
+		blocks = append(blocks, slackSection(":warning: "+l.T("international_calls")))
+	}
+	return append(blocks, slackContext(l.T("disclaimer")))
+}
+
+// currentSlackBlocks returns the blocks of a report of the current billing period including its forecast.
+func currentSlackBlocks(l *i18n.Locale, r *report.Report) []slackBlock {
+	f := r.Forecast
+	blocks := []slackBlock{
+		slackFields(
+			fmt.Sprintf("*%s*\n%s", l.T("national"), slackDuration(r.Usage.National, r.Tariff.NationalQuota)),
+			fmt.Sprintf("*%s*\n%s", l.T("mobile"), slackDuration(r.Usage.Mobile, r.Tariff.MobileQuota)),
+			fmt.Sprintf("*%s*\n%s", l.T("other"), slackDuration(r.Usage.Other, 0)),
+		),
+		{Type: "divider"},
+		slackSection(fmt.Sprintf("*%s*", l.T("forecast"))),
+		slackContext(forecastText(l, f)),
+		slackFields(
+			fmt.Sprintf("*%s*\n%s\n_%s_", quotaLabel(l, "national", r.Tariff.NationalQuota), slackMinutes(l, f.Estimate.National, r.Tariff.NationalQuota, slackEmojiGood), formatRange(l, f.Low.National, f.High.National)),
+			fmt.Sprintf("*%s*\n%s\n_%s_", quotaLabel(l, "mobile", r.Tariff.MobileQuota), slackMinutes(l, f.Estimate.Mobile, r.Tariff.MobileQuota, slackEmojiGood), formatRange(l, f.Low.Mobile, f.High.Mobile)),
+			fmt.Sprintf("*%s*\n%s\n_%s_", l.T("other"), slackMinutes(l, f.Estimate.Other, 0, slackEmojiGood), formatRange(l, f.Low.Other, f.High.Other)),
+		),
+		slackContext(confidenceText(l, f)),
+	}
+	if exhaustion := exhaustionText(l, f); exhaustion != "" {
+		blocks = append(blocks, slackSection(":warning: "+strings.ReplaceAll(exhaustion, "\n\n", "\n:warning: ")))
+	}
+	blocks = append(blocks, slackSection(fmt.Sprintf("*%s:* %s", l.T("additional_cost"), formatCost(l, r.Cost()))))
+	if r.Usage.Other > 0 {
+		blocks = append(blocks, slackSection(":warning: "+l.T("international_calls")))
+	}
+	return blocks
+}
+
+// summarySlackBlocks returns the blocks of a combined report of several accounts.
+// Block Kit does not support tables, so every account is rendered as a separate section.
+func summarySlackBlocks(l *i18n.Locale, r *report.Report) []slackBlock {
+	var blocks []slackBlock
+	for _, a := range r.Accounts {
+		blocks = append(blocks, slackSection(fmt.Sprintf("*%s*\n%s", a.Account, accountSummary(l, a, slackSummaryMinutes))))
+	}
+	return append(blocks,
+		slackBlock{Type: "divider"},
+		slackSection(fmt.Sprintf("*%s:* %s", l.T("additional_total"), formatCost(l, r.Cost()))),
+	)
+}
+
+// slackEmojiGood marks values that are well below their quota.
+const slackEmojiGood = ":large_green_circle:"
+
+// slackEmoji chooses an emoji for d depending on how near d is to its quota.
+// It uses the same thresholds as the colors of the Teams cards.
+// For values that are well below their quota good is returned, which may be empty.
+func slackEmoji(d, quota time.Duration, good string) string {
+	switch report.QuotaStatus(d, quota) {
+	case report.StatusGood:
+		return good
+	case report.StatusWarning:
+		return ":large_yellow_circle:"
+	default:
+		return ":red_circle:"
+	}
+}
+
+// slackMinutes formats d in minutes prefixed with an emoji that indicates how near d is to its quota.
+func slackMinutes(l *i18n.Locale, d, quota time.Duration, good string) string {
+	return strings.TrimSpace(slackEmoji(d, quota, good) + " " + formatMinutes(l, d))
+}
+
+// slackSummaryMinutes formats d in minutes prefixed with an emoji only if d is near its quota.
+func slackSummaryMinutes(l *i18n.Locale, d, quota time.Duration) string {
+	return slackMinutes(l, d, quota, "")
+}
+
+// slackDuration formats d as mm:ss prefixed with an emoji if d is near its quota.
+func slackDuration(d, quota time.Duration) string {
+	return strings.TrimSpace(slackEmoji(d, quota, "") + " " + report.FormatDuration(d))
+}
+
+// slackSection returns a section block with the Markdown text.
+func slackSection(text string) slackBlock {
+	return slackBlock{Type: "section", Text: &slackText{"mrkdwn", text}}
+}
+
+// slackFields returns a section block with the Markdown fields.
+func slackFields(fields ...string) slackBlock {
+	b := slackBlock{Type: "section"}
+	for _, f := range fields {
+		b.Fields = append(b.Fields, slackText{"mrkdwn", f})
+	}
+	return b
+}
+
+// slackContext returns a context block with the Markdown text.
+func slackContext(text string) slackBlock {
+	return slackBlock{Type: "context", Elements: []slackText{{"mrkdwn", text}}}
+}
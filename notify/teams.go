@@ -0,0 +1,94 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+
+	goteamsnotify "github.com/atc0005/go-teams-notify/v2"
+	"github.com/atc0005/go-teams-notify/v2/adaptivecard"
+
+	"github.com/lmr-hh/easybell-billing-info/i18n"
+	"github.com/lmr-hh/easybell-billing-info/report"
+)
+
+// These constants identify the kinds of Teams webhook URLs.
+const (
+	// TeamsAuto detects the kind of a webhook from its URL.
+	TeamsAuto = "auto"
+	// TeamsConnector is an Office 365 connector webhook (incoming webhook).
+	TeamsConnector = "connector"
+	// TeamsWorkflow is a webhook trigger of a Teams Workflow or Power Automate flow.
+	TeamsWorkflow = "workflow"
+)
+
+// TeamsWebhookTypes contains the supported kinds of Teams webhook URLs.
+var TeamsWebhookTypes = []string{TeamsAuto, TeamsConnector, TeamsWorkflow}
+
+// The patterns are those with which goteamsnotify validates webhook URLs.
+var (
+	teamsConnectorPattern = regexp.MustCompile(goteamsnotify.DefaultWebhookURLValidationPattern)
+	teamsWorkflowPattern  = regexp.MustCompile(goteamsnotify.WorkflowURLBaseDomain)
+)
+
+// TeamsWebhookType detects the kind of the Teams webhook at webhookURL.
+// The returned error does not contain the URL because it is a secret.
+func TeamsWebhookType(webhookURL string) (string, error) {
+	switch {
+	case teamsWorkflowPattern.MatchString(webhookURL):
+		return TeamsWorkflow, nil
+	case teamsConnectorPattern.MatchString(webhookURL):
+		return TeamsConnector, nil
+	default:
+		return "", errors.New("unknown kind of Teams webhook URL")
+	}
+}
+
+// Teams sends reports as Adaptive Cards to a Microsoft Teams webhook.
+type Teams struct {
+	client     *goteamsnotify.TeamsClient
+	webhookURL string
+	templates  *CardTemplates
+	locale     *i18n.Locale
+}
+
+// NewTeams creates a new notifier that uses client to send reports to webhookURL.
+// The cards are rendered from templates, which may be nil to use the embedded templates,
+// in the language of l.
+func NewTeams(client *goteamsnotify.TeamsClient, webhookURL string, templates *CardTemplates, l *i18n.Locale) *Teams {
+	return &Teams{client, webhookURL, templates, l}
+}
+
+// Notify sends r as an Adaptive Card to the webhook of t.
+// The goteamsnotify client validates the URL and the response of connectors and workflows.
+func (t *Teams) Notify(ctx context.Context, r *report.Report) error {
+	msg, err := t.message(r)
+	if err != nil {
+		return fmt.Errorf("teams: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, goteamsnotify.DefaultWebhookSendTimeout)
+	defer cancel()
+	if err = t.client.SendWithContext(ctx, t.webhookURL, msg); err != nil {
+		return fmt.Errorf("teams: %w", err)
+	}
+	return nil
+}
+
+// Render returns the message with the Adaptive Card that t sends for r.
+func (t *Teams) Render(r *report.Report) (*Payload, error) {
+	msg, err := t.message(r)
+	if err != nil {
+		return nil, fmt.Errorf("teams: %w", err)
+	}
+	return jsonPayload("teams", msg)
+}
+
+// message renders r as a message with a single Adaptive Card.
+func (t *Teams) message(r *report.Report) (*adaptivecard.Message, error) {
+	card, err := t.templates.Card(r, t.locale)
+	if err != nil {
+		return nil, err
+	}
+	return adaptivecard.NewMessageFromCard(card)
+}
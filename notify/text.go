@@ -0,0 +1,149 @@
+package notify
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/lmr-hh/easybell-billing-info/i18n"
+	"github.com/lmr-hh/easybell-billing-info/report"
+)
+
+// heading returns a heading for r.
+func heading(l *i18n.Locale, r *report.Report) string {
+	switch {
+	case r.Kind == report.KindCurrent:
+		return l.T("heading.current")
+	case r.Kind == report.KindSummary && len(r.Accounts) > 0 && r.Accounts[0].Kind == report.KindCurrent:
+		return l.T("heading.forecast")
+	default:
+		return l.T("heading.previous")
+	}
+}
+
+// title returns a title for r consisting of the account name and the period.
+func title(l *i18n.Locale, r *report.Report) string {
+	switch {
+	case r.Kind == report.KindSummary:
+		return l.T("title.all", r.PeriodName(l))
+	case r.Account == "":
+		return r.PeriodName(l)
+	default:
+		return fmt.Sprintf("%s · %s", r.Account, r.PeriodName(l))
+	}
+}
+
+// alertTexts describes the thresholds whose crossing triggered r, e.g. "Mobile usage has reached 90 % of the quota (92.5 %)."
+// It is empty unless r is sent by the alert command.
+func alertTexts(l *i18n.Locale, r *report.Report) []string {
+	texts := make([]string, len(r.Alerts))
+	for i, a := range r.Alerts {
+		texts[i] = a.Text(l)
+	}
+	return texts
+}
+
+// alertLines returns the alert texts of r as emphasized Markdown lines.
+func alertLines(l *i18n.Locale, r *report.Report) []string {
+	lines := alertTexts(l, r)
+	for i, text := range lines {
+		lines[i] = "🔔 **" + text + "**"
+	}
+	return lines
+}
+
+// quotaLabel returns the label of a kind of usage including its quota in minutes.
+func quotaLabel(l *i18n.Locale, key string, quota time.Duration) string {
+	return l.T("quota", l.T(key), l.Number(quota.Minutes(), 0))
+}
+
+// forecastText returns a description of the basis of f.
+func forecastText(l *i18n.Locale, f *report.Forecast) string {
+	return l.T("forecast.text", l.Number(f.Window.Hours()/24, 0))
+}
+
+// confidenceText returns a description of the confidence level of f.
+func confidenceText(l *i18n.Locale, f *report.Forecast) string {
+	return l.T("forecast.confidence", l.Number(f.Level*100, 0))
+}
+
+// exhaustionText returns a description of the days on which the quotas are projected to be exhausted.
+// If no quota is exhausted, the empty string is returned.
+func exhaustionText(l *i18n.Locale, f *report.Forecast) string {
+	var lines []string
+	if !f.NationalExhausted.IsZero() {
+		lines = append(lines, l.T("exhausted.national", l.ShortDate(f.NationalExhausted)))
+	}
+	if !f.MobileExhausted.IsZero() {
+		lines = append(lines, l.T("exhausted.mobile", l.ShortDate(f.MobileExhausted)))
+	}
+	return strings.Join(lines, "\n\n")
+}
+
+// alertTitle returns a short title for an alert about the push status of r.
+func alertTitle(l *i18n.Locale, r *report.Report) string {
+	text := l.T("alert." + pushStatus(r).String())
+	if r.Account != "" {
+		return fmt.Sprintf("easyBell %s: %s", r.Account, text)
+	}
+	return "easyBell: " + text
+}
+
+// alertText returns a short description of the usage of r that is near or over its quotas.
+// The thresholds that triggered r are described first.
+func alertText(l *i18n.Locale, r *report.Report) string {
+	lines := alertTexts(l, r)
+	if r.Kind == report.KindSummary {
+		for _, a := range r.Accounts {
+			if quotas := alertQuotas(l, a); len(quotas) > 0 {
+				lines = append(lines, fmt.Sprintf("%s: %s", a.Account, strings.Join(quotas, ", ")))
+			}
+		}
+	} else {
+		quotas := alertQuotas(l, r)
+		if r.Kind == report.KindCurrent {
+			quotas = append([]string{l.T("forecast") + ":"}, quotas...)
+		}
+		lines = append(lines, quotas...)
+	}
+	lines = append(lines, fmt.Sprintf("%s: %s", l.T("additional_cost"), formatCost(l, r.Cost())))
+	return strings.Join(lines, "\n")
+}
+
+// alertQuotas describes every kind of expected usage of r that is near or over its quota.
+func alertQuotas(l *i18n.Locale, r *report.Report) []string {
+	u := r.ExpectedUsage()
+	var quotas []string
+	for _, q := range []struct {
+		key      string
+		d, quota time.Duration
+	}{
+		{"national", u.National, r.Tariff.NationalQuota},
+		{"mobile", u.Mobile, r.Tariff.MobileQuota},
+	} {
+		if report.QuotaStatus(q.d, q.quota) != report.StatusGood {
+			percent := l.Number(100*q.d.Minutes()/q.quota.Minutes(), 0)
+			quotas = append(quotas, l.T("alert.quota", l.T(q.key), formatMinutes(l, q.d), l.Number(q.quota.Minutes(), 0), percent))
+		}
+	}
+	if u.Other > 0 {
+		quotas = append(quotas, l.T("alert.international", formatMinutes(l, u.Other)))
+	}
+	return quotas
+}
+
+// formatMinutes formats d as a number of started minutes.
+func formatMinutes(l *i18n.Locale, d time.Duration) string {
+	return l.T("minutes", l.Number(math.Ceil(d.Minutes()), 0))
+}
+
+// formatRange formats the range between low and high in started minutes.
+func formatRange(l *i18n.Locale, low, high time.Duration) string {
+	return l.T("minutes.range", l.Number(math.Ceil(low.Minutes()), 0), l.Number(math.Ceil(high.Minutes()), 0))
+}
+
+// formatCost formats cost in Euro.
+func formatCost(l *i18n.Locale, cost float64) string {
+	return l.Cost(cost)
+}
@@ -0,0 +1,127 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/lmr-hh/easybell-billing-info/report"
+)
+
+// These headers are sent with every webhook request.
+const (
+	// WebhookSignatureHeader contains the HMAC-SHA256 signature of the timestamp and the request body as "sha256=<hex>".
+	// It is only sent if a secret is configured.
+	WebhookSignatureHeader = "X-Easybell-Signature-256"
+	// WebhookTimestampHeader contains the time at which the request was sent in Unix seconds.
+	// It is signed together with the body, so receivers can reject replayed requests.
+	WebhookTimestampHeader = "X-Easybell-Timestamp"
+	// WebhookDeliveryHeader contains a unique ID of the report that stays the same across retries.
+	WebhookDeliveryHeader = "X-Easybell-Delivery"
+	// WebhookVersionHeader contains the schema version of the payload.
+	WebhookVersionHeader = "X-Easybell-Schema-Version"
+)
+
+// WebhookTolerance is the maximum difference between the timestamp of a request and the clock of the receiver
+// that receivers should accept. Older requests may be replayed and should be rejected.
+const WebhookTolerance = 5 * time.Minute
+
+// Webhook sends reports as JSON documents to an HTTP endpoint.
+// The payload is the [report.Document] of a report.
+type Webhook struct {
+	URL string
+	// Secret is used to sign the timestamp and the body of requests.
+	// If Secret is empty, requests are not signed.
+	Secret string
+	// Retries is the number of times a failed request is repeated.
+	// Requests are only repeated after network errors, 429 and 5xx responses.
+	Retries int
+	// RetryDelay is the delay before the first retry.
+	// The delay is doubled for every further retry.
+	RetryDelay time.Duration
+}
+
+// Notify sends the document of r to the URL of w.
+func (w *Webhook) Notify(ctx context.Context, r *report.Report) error {
+	body, err := json.Marshal(r.Document())
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	header := http.Header{
+		"Content-Type":        {"application/json"},
+		WebhookDeliveryHeader: {randomID()},
+		WebhookVersionHeader:  {strconv.Itoa(report.SchemaVersion)},
+	}
+
+	delay := w.RetryDelay
+	for attempt := 0; ; attempt++ {
+		// Every attempt is signed with its own timestamp so that late retries are not rejected as replays.
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		header.Set(WebhookTimestampHeader, timestamp)
+		if w.Secret != "" {
+			header.Set(WebhookSignatureHeader, Sign(w.Secret, timestamp, body))
+		}
+		err = post(ctx, http.DefaultClient, w.URL, header, body)
+		if err == nil || attempt >= w.Retries || !retryable(err) {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("webhook: %w", errors.Join(err, ctx.Err()))
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	return nil
+}
+
+// Render returns the document that w sends for r.
+func (w *Webhook) Render(r *report.Report) (*Payload, error) {
+	return jsonPayload("webhook", r.Document())
+}
+
+// Sign returns the value of the signature header for body sent at timestamp, signed with secret.
+// The signed message is the value of the timestamp header, a dot and the body.
+// Receivers should compute the same value and compare it using [hmac.Equal].
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks that the request with header and body was signed with secret
+// and that its timestamp is within [WebhookTolerance] of now.
+func Verify(secret string, header http.Header, body []byte, now time.Time) error {
+	timestamp := header.Get(WebhookTimestampHeader)
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.New("invalid timestamp")
+	}
+	if d := now.Sub(time.Unix(seconds, 0)); d > WebhookTolerance || d < -WebhookTolerance {
+		return errors.New("timestamp outside of tolerance")
+	}
+	if !hmac.Equal([]byte(header.Get(WebhookSignatureHeader)), []byte(Sign(secret, timestamp, body))) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+// retryable indicates whether a request that failed with err should be repeated.
+func retryable(err error) bool {
+	var status *statusError
+	if errors.As(err, &status) {
+		return status.code == http.StatusTooManyRequests || status.code >= 500
+	}
+	return !errors.Is(err, errInvalidURL) && !errors.Is(err, context.Canceled)
+}
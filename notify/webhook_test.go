@@ -0,0 +1,92 @@
+package notify
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lmr-hh/easybell-billing-info/report"
+)
+
+func TestSign(t *testing.T) {
+	// Computed with: printf '1760000000.{}' | openssl dgst -sha256 -hmac secret
+	want := "sha256=53dc054739ad94d3532227ba8d397f66ed2166a6b66940c2006692fa6db6829f"
+	if got := Sign("secret", "1760000000", []byte("{}")); got != want {
+		t.Errorf("Sign = %s, want %s", got, want)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1760000000, 0)
+	body := []byte(`{"version":1}`)
+	header := func(timestamp time.Time, secret string, body []byte) http.Header {
+		ts := strconv.FormatInt(timestamp.Unix(), 10)
+		return http.Header{
+			WebhookTimestampHeader: {ts},
+			WebhookSignatureHeader: {Sign(secret, ts, body)},
+		}
+	}
+	tests := []struct {
+		name   string
+		header http.Header
+		valid  bool
+	}{
+		{"valid", header(now, "secret", body), true},
+		{"within tolerance", header(now.Add(-WebhookTolerance), "secret", body), true},
+		{"clock skew", header(now.Add(time.Minute), "secret", body), true},
+		{"too old", header(now.Add(-WebhookTolerance-time.Second), "secret", body), false},
+		{"too new", header(now.Add(WebhookTolerance+time.Second), "secret", body), false},
+		{"wrong secret", header(now, "other", body), false},
+		{"other body", header(now, "secret", []byte(`{"version":2}`)), false},
+		{"missing timestamp", http.Header{WebhookSignatureHeader: {Sign("secret", "", body)}}, false},
+		{"missing signature", http.Header{WebhookTimestampHeader: {strconv.FormatInt(now.Unix(), 10)}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify("secret", tt.header, body, now)
+			if tt.valid && err != nil {
+				t.Errorf("Verify = %v, want nil", err)
+			}
+			if !tt.valid && err == nil {
+				t.Error("Verify succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestWebhookNotify(t *testing.T) {
+	var mu sync.Mutex
+	var headers []http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		headers = append(headers, r.Header.Clone())
+		if err := Verify("secret", r.Header, body, time.Now()); err != nil {
+			t.Errorf("request %d: %v", len(headers), err)
+		}
+		if len(headers) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	w := &Webhook{URL: server.URL, Secret: "secret", Retries: 1, RetryDelay: time.Millisecond}
+	if err := w.Notify(context.Background(), sampleReports()[report.KindPrevious]); err != nil {
+		t.Fatal(err)
+	}
+	if len(headers) != 2 {
+		t.Fatalf("webhook received %d requests, want 2", len(headers))
+	}
+	if a, b := headers[0].Get(WebhookDeliveryHeader), headers[1].Get(WebhookDeliveryHeader); a == "" || a != b {
+		t.Errorf("delivery IDs %q and %q differ", a, b)
+	}
+	if v := headers[0].Get(WebhookVersionHeader); v != strconv.Itoa(report.SchemaVersion) {
+		t.Errorf("schema version = %q, want %d", v, report.SchemaVersion)
+	}
+}
@@ -0,0 +1,31 @@
+package report
+
+import "github.com/lmr-hh/easybell-billing-info/i18n"
+
+// These constants identify the values that the thresholds of alerts apply to.
+const (
+	// AlertNational is the percentage of the national quota used so far.
+	AlertNational = "national"
+	// AlertMobile is the percentage of the mobile quota used so far.
+	AlertMobile = "mobile"
+	// AlertCost is the projected additional cost at the end of the period.
+	AlertCost = "cost"
+)
+
+// An Alert is a threshold that has been crossed by the usage of a report.
+type Alert struct {
+	// Kind is one of the Alert constants.
+	Kind string
+	// Threshold is a percentage of a quota or an amount in Euro, depending on Kind.
+	Threshold float64
+	// Value is the value that crossed the threshold.
+	Value float64
+}
+
+// Text describes a in the language of l, e.g. "Mobile usage has reached 90 % of the quota (92.5 %)."
+func (a Alert) Text(l *i18n.Locale) string {
+	if a.Kind == AlertCost {
+		return l.T("threshold.cost", l.Cost(a.Threshold), l.Cost(a.Value))
+	}
+	return l.T("threshold."+a.Kind, l.Number(a.Threshold, 0), l.Number(a.Value, 2))
+}
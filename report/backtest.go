@@ -0,0 +1,202 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/lmr-hh/easybell-billing-info/i18n"
+)
+
+// A Backtest is the accuracy of forecast models on past billing periods of an account.
+type Backtest struct {
+	// Account is the name of the account.
+	// If only a single account is configured, the name is empty.
+	Account string
+	// Start and End delimit the replayed billing periods.
+	Start time.Time
+	End   time.Time
+	// Results contains the accuracy of every combination of model and estimation period.
+	Results []BacktestResult
+}
+
+// A BacktestResult is the accuracy of a forecast model with an estimation period.
+type BacktestResult struct {
+	Model  string
+	Window time.Duration
+	// Forecasts is the number of forecasts that were compared with the actual usage.
+	Forecasts int
+	National  ForecastError
+	Mobile    ForecastError
+}
+
+// ForecastError contains the mean errors of forecasts compared to the actual usage.
+type ForecastError struct {
+	// MAE is the mean absolute error.
+	MAE time.Duration
+	// MAPE is the mean absolute percentage error.
+	MAPE float64
+	// Bias is the mean signed error.
+	// A positive bias indicates that the model overestimates the usage.
+	Bias time.Duration
+}
+
+// BacktestDocument is the stable, serializable representation of a [Backtest].
+// Its Kind is always [KindBacktest].
+type BacktestDocument struct {
+	Version int                      `json:"version" yaml:"version"`
+	Kind    Kind                     `json:"kind" yaml:"kind"`
+	Account string                   `json:"account,omitempty" yaml:"account,omitempty"`
+	Period  DocumentPeriod           `json:"period" yaml:"period"`
+	Results []DocumentBacktestResult `json:"results" yaml:"results"`
+}
+
+// DocumentBacktestResult is the accuracy of a forecast model with an estimation period.
+type DocumentBacktestResult struct {
+	Model         string                `json:"model" yaml:"model"`
+	WindowSeconds float64               `json:"window_seconds" yaml:"window_seconds"`
+	Forecasts     int                   `json:"forecasts" yaml:"forecasts"`
+	National      DocumentForecastError `json:"national" yaml:"national"`
+	Mobile        DocumentForecastError `json:"mobile" yaml:"mobile"`
+}
+
+// DocumentForecastError contains the mean errors of forecasts.
+// The MAPE is a percentage.
+type DocumentForecastError struct {
+	MAESeconds  float64 `json:"mae_seconds" yaml:"mae_seconds"`
+	MAPE        float64 `json:"mape" yaml:"mape"`
+	BiasSeconds float64 `json:"bias_seconds" yaml:"bias_seconds"`
+}
+
+// Document returns the serializable representation of b.
+func (b *Backtest) Document() BacktestDocument {
+	d := BacktestDocument{
+		Version: SchemaVersion,
+		Kind:    KindBacktest,
+		Account: b.Account,
+		Period:  DocumentPeriod{b.Start, b.End},
+		Results: make([]DocumentBacktestResult, len(b.Results)),
+	}
+	for i, r := range b.Results {
+		d.Results[i] = DocumentBacktestResult{
+			Model:         r.Model,
+			WindowSeconds: r.Window.Seconds(),
+			Forecasts:     r.Forecasts,
+			National:      r.National.document(),
+			Mobile:        r.Mobile.document(),
+		}
+	}
+	return d
+}
+
+// document returns the serializable representation of e.
+func (e ForecastError) document() DocumentForecastError {
+	return DocumentForecastError{MAESeconds: e.MAE.Seconds(), MAPE: e.MAPE, BiasSeconds: e.Bias.Seconds()}
+}
+
+// backtestColumns contains the header of the CSV representation of backtests.
+// Every result of a backtest is a row.
+var backtestColumns = []string{
+	"version", "kind", "account", "period_start", "period_end",
+	"model", "window_seconds", "forecasts",
+	"national_mae_seconds", "national_mape", "national_bias_seconds",
+	"mobile_mae_seconds", "mobile_mape", "mobile_bias_seconds",
+}
+
+// backtestRecords returns the CSV records of d in the order of backtestColumns.
+func backtestRecords(d BacktestDocument) [][]string {
+	number := func(f float64) string { return strconv.FormatFloat(f, 'f', -1, 64) }
+	records := make([][]string, len(d.Results))
+	for i, r := range d.Results {
+		records[i] = []string{
+			strconv.Itoa(d.Version), string(d.Kind), d.Account,
+			d.Period.Start.Format(time.RFC3339), d.Period.End.Format(time.RFC3339),
+			r.Model, number(r.WindowSeconds), strconv.Itoa(r.Forecasts),
+			number(r.National.MAESeconds), number(r.National.MAPE), number(r.National.BiasSeconds),
+			number(r.Mobile.MAESeconds), number(r.Mobile.MAPE), number(r.Mobile.BiasSeconds),
+		}
+	}
+	return records
+}
+
+// WriteBacktestText writes b as a plain text table in the language of l to w.
+func WriteBacktestText(w io.Writer, b *Backtest, l *i18n.Locale) error {
+	t := &textWriter{w: w, l: l}
+	t.printf("%s\n\n", t.backtestTitle(b))
+	if t.err != nil {
+		return t.err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	for _, row := range t.backtestRows(b) {
+		for _, cell := range row {
+			_, _ = fmt.Fprintf(tw, "%s\t", cell)
+		}
+		_, _ = fmt.Fprintln(tw)
+	}
+	if t.err = tw.Flush(); t.err != nil {
+		return t.err
+	}
+	t.printf("\n%s\n%s\n\n", l.T("backtest.errors"), l.T("backtest.bias"))
+	return t.err
+}
+
+// WriteBacktestMarkdown writes b as a Markdown table in the language of l to w.
+func WriteBacktestMarkdown(w io.Writer, b *Backtest, l *i18n.Locale) error {
+	t := &textWriter{w: w, l: l}
+	t.printf("## %s\n\n", markdownEscaper.Replace(t.backtestTitle(b)))
+	for i, row := range t.backtestRows(b) {
+		t.printf("|")
+		for _, cell := range row {
+			t.printf(" %s |", markdownEscaper.Replace(cell))
+		}
+		t.printf("\n")
+		if i == 0 {
+			t.printf("| --- |")
+			for range row[1:] {
+				t.printf(" ---: |")
+			}
+			t.printf("\n")
+		}
+	}
+	t.printf("\n_%s %s_\n\n", l.T("backtest.errors"), l.T("backtest.bias"))
+	return t.err
+}
+
+// backtestTitle returns the title of b including the account name.
+func (t *textWriter) backtestTitle(b *Backtest) string {
+	title := t.l.T("backtest.title", t.l.Date(b.Start), t.l.Date(b.End.AddDate(0, 0, -1)))
+	if b.Account == "" {
+		return title
+	}
+	return fmt.Sprintf("%s (%s)", title, b.Account)
+}
+
+// backtestRows returns the header and the rows of the table of b.
+func (t *textWriter) backtestRows(b *Backtest) [][]string {
+	national, mobile := t.l.T("text.national"), t.l.T("text.mobile")
+	rows := [][]string{{
+		t.l.T("backtest.model"), t.l.T("backtest.window"), t.l.T("backtest.forecasts"),
+		national + " MAE", national + " MAPE", national + " Bias",
+		mobile + " MAE", mobile + " MAPE", mobile + " Bias",
+	}}
+	for _, r := range b.Results {
+		rows = append(rows, []string{
+			r.Model, t.l.T("backtest.days", t.l.Number(r.Window.Hours()/24, 0)), strconv.Itoa(r.Forecasts),
+			t.minutes(r.National.MAE, false), t.l.Number(r.National.MAPE, 1) + " %", t.minutes(r.National.Bias, true),
+			t.minutes(r.Mobile.MAE, false), t.l.Number(r.Mobile.MAPE, 1) + " %", t.minutes(r.Mobile.Bias, true),
+		})
+	}
+	return rows
+}
+
+// minutes formats d in minutes with one fraction digit.
+// If signed is true, positive values are prefixed by a plus sign.
+func (t *textWriter) minutes(d time.Duration, signed bool) string {
+	s := t.l.T("backtest.minutes", t.l.Number(d.Minutes(), 1))
+	if signed && d.Minutes() >= 0.05 {
+		return "+" + s
+	}
+	return s
+}
@@ -0,0 +1,38 @@
+package report
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/lmr-hh/easybell-billing-info/easybell"
+)
+
+// callColumns contains the header of the CSV representation of calls.
+var callColumns = []string{"time", "number", "partner", "direction", "type", "kind", "status", "duration_seconds"}
+
+// WriteCallsCSV writes calls as CSV with a header line to w.
+func WriteCallsCSV(w io.Writer, calls []easybell.CallLogEntry) error {
+	c := csv.NewWriter(w)
+	if err := c.Write(callColumns); err != nil {
+		return err
+	}
+	for _, e := range calls {
+		record := []string{
+			e.Time.Format(time.RFC3339),
+			e.Number,
+			e.Partner,
+			e.Direction,
+			e.CallType,
+			e.Kind,
+			e.Status,
+			strconv.FormatFloat(e.Duration.Seconds(), 'f', -1, 64),
+		}
+		if err := c.Write(record); err != nil {
+			return err
+		}
+	}
+	c.Flush()
+	return c.Error()
+}
@@ -0,0 +1,77 @@
+package report
+
+import (
+	"io"
+	"strings"
+
+	"github.com/lmr-hh/easybell-billing-info/easybell"
+	"github.com/lmr-hh/easybell-billing-info/i18n"
+)
+
+// markdownEscaper escapes characters that would break Markdown tables or emphasis.
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "|", `\|`, "*", `\*`, "_", `\_`)
+
+// WriteMarkdown writes r as Markdown in the language of l to w.
+// Usage values are formatted as tables, e.g. for wikis or pull request comments.
+func WriteMarkdown(w io.Writer, r *Report, l *i18n.Locale) error {
+	t := &textWriter{w: w, l: l}
+	switch r.Kind {
+	case KindSummary:
+		t.markdownSummary(r)
+	default:
+		t.markdownReport(r)
+	}
+	return t.err
+}
+
+// markdownReport writes a report of a single account.
+func (t *textWriter) markdownReport(r *Report) {
+	t.printf("## %s\n\n", markdownEscaper.Replace(t.l.T("text.title", r.Name(t.l))))
+	for _, a := range r.Alerts {
+		t.printf("> %s\n", a.Text(t.l))
+	}
+	if len(r.Alerts) > 0 {
+		t.printf("\n")
+	}
+	t.printf("| | %s | %s | %s |\n", t.l.T("text.national"), t.l.T("text.mobile"), t.l.T("text.international"))
+	t.printf("| --- | ---: | ---: | ---: |\n")
+	t.markdownUsage(t.l.T("markdown.usage"), r.Usage)
+	if f := r.Forecast; f != nil {
+		t.markdownUsage(t.l.T("markdown.estimate"), f.Estimate)
+		t.printf("| %s | %s – %s | %s – %s | %s – %s |\n", t.l.T("markdown.range", t.l.Number(f.Level*100, 0)),
+			FormatDuration(f.Low.National), FormatDuration(f.High.National),
+			FormatDuration(f.Low.Mobile), FormatDuration(f.High.Mobile),
+			FormatDuration(f.Low.Other), FormatDuration(f.High.Other))
+	}
+	t.markdownUsage(t.l.T("markdown.quota"), easybell.Usage{National: r.Tariff.NationalQuota, Mobile: r.Tariff.MobileQuota})
+	t.printf("\n**%s:** %s\n\n", t.l.T("text.cost"), t.l.Cost(r.Cost()))
+	if f := r.Forecast; f != nil {
+		if !f.NationalExhausted.IsZero() {
+			t.printf("%s\n\n", t.l.T("text.exhausted.national", t.l.ShortDate(f.NationalExhausted)))
+		}
+		if !f.MobileExhausted.IsZero() {
+			t.printf("%s\n\n", t.l.T("text.exhausted.mobile", t.l.ShortDate(f.MobileExhausted)))
+		}
+		t.printf("_%s_\n\n", t.l.T("text.basis", f.Model, t.l.Number(f.Window.Hours()/24, 1)))
+	}
+}
+
+// markdownUsage writes a table row with the values of u.
+func (t *textWriter) markdownUsage(label string, u easybell.Usage) {
+	t.printf("| %s | %s | %s | %s |\n", label, FormatDuration(u.National), FormatDuration(u.Mobile), FormatDuration(u.Other))
+}
+
+// markdownSummary writes a combined report of several accounts as a table.
+func (t *textWriter) markdownSummary(r *Report) {
+	t.printf("## %s\n\n", t.l.T("text.summary"))
+	if len(r.Accounts) > 0 && r.Accounts[0].Kind == KindCurrent {
+		t.printf("_%s_\n\n", t.l.T("text.forecast"))
+	}
+	t.printf("| %s | %s | %s | %s | %s |\n", t.l.T("account"), t.l.T("text.national"), t.l.T("text.mobile"), t.l.T("text.international"), t.l.T("text.cost"))
+	t.printf("| --- | ---: | ---: | ---: | ---: |\n")
+	for _, a := range r.Accounts {
+		u := a.ExpectedUsage()
+		t.printf("| %s | %s | %s | %s | %s |\n", markdownEscaper.Replace(a.Account), FormatDuration(u.National), FormatDuration(u.Mobile), FormatDuration(u.Other), t.l.Cost(a.Cost()))
+	}
+	t.printf("| **%s** | | | | **%s** |\n\n", t.l.T("text.total"), t.l.Cost(r.Cost()))
+}
@@ -0,0 +1,171 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/lmr-hh/easybell-billing-info/i18n"
+)
+
+// These constants identify the output formats of reports.
+const (
+	FormatText     = "text"
+	FormatJSON     = "json"
+	FormatYAML     = "yaml"
+	FormatCSV      = "csv"
+	FormatMarkdown = "markdown"
+)
+
+// Formats contains all output formats.
+var Formats = []string{FormatText, FormatJSON, FormatYAML, FormatCSV, FormatMarkdown}
+
+// A Writer writes a sequence of reports in an output format.
+//
+// The machine-readable formats use the [Document] schema:
+// JSON is written as one document per line, YAML as a stream of documents
+// and CSV as a table with a header line and one row per report.
+// Text and Markdown are written in the language of the writer.
+type Writer struct {
+	w      io.Writer
+	format string
+	l      *i18n.Locale
+	yaml   *yaml.Encoder
+	csv    *csv.Writer
+}
+
+// NewWriter returns a writer that writes reports in format to w.
+// The locale l is used for the text and Markdown formats.
+func NewWriter(w io.Writer, format string, l *i18n.Locale) (*Writer, error) {
+	if !slices.Contains(Formats, format) {
+		return nil, fmt.Errorf("invalid output format %q: must be one of %s", format, strings.Join(Formats, ", "))
+	}
+	return &Writer{w: w, format: format, l: l}, nil
+}
+
+// Write writes r.
+func (w *Writer) Write(r *Report) error {
+	switch w.format {
+	case FormatJSON, FormatYAML:
+		return w.encode(r.Document())
+	case FormatCSV:
+		return w.writeCSV(documentColumns, documentRecord(r.Document()))
+	case FormatMarkdown:
+		return WriteMarkdown(w.w, r, w.l)
+	default:
+		return WriteText(w.w, r, w.l)
+	}
+}
+
+// WriteBacktest writes b.
+// The machine-readable formats use the [BacktestDocument] schema, CSV has a row per result.
+// A writer should not be used for both reports and backtests since their CSV columns differ.
+func (w *Writer) WriteBacktest(b *Backtest) error {
+	switch w.format {
+	case FormatJSON, FormatYAML:
+		return w.encode(b.Document())
+	case FormatCSV:
+		return w.writeCSV(backtestColumns, backtestRecords(b.Document())...)
+	case FormatMarkdown:
+		return WriteBacktestMarkdown(w.w, b, w.l)
+	default:
+		return WriteBacktestText(w.w, b, w.l)
+	}
+}
+
+// encode writes the document d in the JSON or YAML format.
+func (w *Writer) encode(d any) error {
+	if w.format == FormatJSON {
+		return json.NewEncoder(w.w).Encode(d)
+	}
+	if w.yaml == nil {
+		w.yaml = yaml.NewEncoder(w.w)
+		w.yaml.SetIndent(2)
+	}
+	return w.yaml.Encode(d)
+}
+
+// writeCSV writes records as CSV.
+// The header is written before the first records.
+func (w *Writer) writeCSV(header []string, records ...[]string) error {
+	if w.csv == nil {
+		w.csv = csv.NewWriter(w.w)
+		if err := w.csv.Write(header); err != nil {
+			return err
+		}
+	}
+	// Every write is flushed so that the output of long-running commands is not delayed.
+	return w.csv.WriteAll(records)
+}
+
+// Close completes the output.
+// It does not close the underlying writer.
+func (w *Writer) Close() error {
+	if w.yaml != nil {
+		return w.yaml.Close()
+	}
+	return nil
+}
+
+// documentColumns contains the header of the CSV representation of documents.
+// Nested values are flattened, columns of missing values are empty.
+// The alerts column contains the kind and threshold of every alert separated by spaces, e.g. "mobile:90 cost:10".
+var documentColumns = []string{
+	"version", "kind", "account", "period_start", "period_end", "status",
+	"national_seconds", "mobile_seconds", "international_seconds",
+	"national_quota_seconds", "mobile_quota_seconds", "national_minute_price", "mobile_minute_price",
+	"forecast_model", "forecast_window_seconds", "forecast_level",
+	"forecast_national_seconds", "forecast_mobile_seconds", "forecast_international_seconds",
+	"forecast_low_national_seconds", "forecast_low_mobile_seconds", "forecast_low_international_seconds",
+	"forecast_high_national_seconds", "forecast_high_mobile_seconds", "forecast_high_international_seconds",
+	"forecast_national_exhausted", "forecast_mobile_exhausted",
+	"cost", "currency", "alerts",
+}
+
+// documentRecord returns the CSV record of d in the order of documentColumns.
+func documentRecord(d Document) []string {
+	number := func(f float64) string { return strconv.FormatFloat(f, 'f', -1, 64) }
+	timestamp := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+	usage := func(u *DocumentUsage) []string {
+		if u == nil {
+			return []string{"", "", ""}
+		}
+		return []string{number(u.NationalSeconds), number(u.MobileSeconds), number(u.InternationalSeconds)}
+	}
+	record := []string{
+		strconv.Itoa(d.Version), string(d.Kind), d.Account,
+		timestamp(&d.Period.Start), timestamp(&d.Period.End), d.Status.String(),
+	}
+	record = append(record, usage(d.Usage)...)
+	if t := d.Tariff; t != nil {
+		record = append(record, number(t.NationalQuotaSeconds), number(t.MobileQuotaSeconds), number(t.NationalMinutePrice), number(t.MobileMinutePrice))
+	} else {
+		record = append(record, "", "", "", "")
+	}
+	if f := d.Forecast; f != nil {
+		record = append(record, f.Model, number(f.WindowSeconds), number(f.Level))
+		record = append(record, usage(&f.Estimate)...)
+		record = append(record, usage(&f.Low)...)
+		record = append(record, usage(&f.High)...)
+		record = append(record, timestamp(f.NationalExhausted), timestamp(f.MobileExhausted))
+	} else {
+		record = append(record, make([]string, 14)...)
+	}
+	alerts := make([]string, len(d.Alerts))
+	for i, a := range d.Alerts {
+		alerts[i] = a.Kind + ":" + number(a.Threshold)
+	}
+	return append(record, number(d.Cost), d.Currency, strings.Join(alerts, " "))
+}
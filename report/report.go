@@ -0,0 +1,198 @@
+// Package report defines a channel-neutral model of easyBell usage reports.
+//
+// A [Report] contains all information that is presented to users, independent of the channel
+// that is used to deliver it. Renderers for specific channels are implemented in other packages.
+package report
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/lmr-hh/easybell-billing-info/easybell"
+)
+
+// Kind identifies the type of a report.
+type Kind string
+
+// These constants identify the known kinds of reports.
+const (
+	// KindPrevious is a report of a completed billing period.
+	KindPrevious Kind = "previous"
+	// KindCurrent is a report of the current billing period including a forecast.
+	KindCurrent Kind = "current"
+	// KindSummary is a combined report of several accounts.
+	KindSummary Kind = "summary"
+	// KindBacktest is the accuracy of the forecast models on past billing periods.
+	// It is only used by [BacktestDocument].
+	KindBacktest Kind = "backtest"
+)
+
+// A Report is the usage of an account in a billing period.
+type Report struct {
+	Kind Kind
+	// Account is the name of the account.
+	// If only a single account is configured, the name is empty.
+	Account string
+	// Start and End delimit the billing period.
+	Start time.Time
+	End   time.Time
+	// Usage is the usage in the billing period.
+	// For reports of the current period this is the usage so far.
+	Usage  easybell.Usage
+	Tariff Tariff
+	// Forecast is the forecast to the end of the period.
+	// It is only set for reports of the current period.
+	Forecast *Forecast
+	// Alerts contains the thresholds whose crossing triggered the report.
+	// It is only set for reports that are sent by the alert command.
+	Alerts []Alert
+	// Accounts contains the reports of the individual accounts of a summary report.
+	Accounts []*Report
+	// Calls optionally contains the calls in the billing period.
+	// It is only set if a notifier requires the individual calls.
+	Calls []easybell.CallLogEntry
+}
+
+// Cost returns the additional cost of r.
+// For reports of the current period the cost of the estimated usage is returned.
+// For summaries the cost of all accounts is returned.
+func (r *Report) Cost() float64 {
+	if r.Kind == KindSummary {
+		var total float64
+		for _, a := range r.Accounts {
+			total += a.Cost()
+		}
+		return total
+	}
+	return r.Tariff.Cost(r.ExpectedUsage())
+}
+
+// ExpectedUsage returns the usage at the end of the period of r.
+// For reports of the current period this is the estimated usage.
+func (r *Report) ExpectedUsage() easybell.Usage {
+	if r.Forecast != nil {
+		return r.Forecast.Estimate
+	}
+	return r.Usage
+}
+
+// IsCalendarMonth indicates whether the period of r is exactly one calendar month.
+func (r *Report) IsCalendarMonth() bool {
+	return r.Start.Day() == 1 && r.Start.AddDate(0, 1, 0).Equal(r.End)
+}
+
+// LastDay returns the last day of the period of r.
+func (r *Report) LastDay() time.Time {
+	return r.End.AddDate(0, 0, -1)
+}
+
+// Tariff contains the included quotas and the prices of additional minutes.
+type Tariff struct {
+	NationalQuota       time.Duration
+	MobileQuota         time.Duration
+	NationalMinutePrice float64
+	MobileMinutePrice   float64
+}
+
+// Cost calculates the expected cost for u being over the quota.
+func (t Tariff) Cost(u easybell.Usage) float64 {
+	return math.Ceil(max(u.National-t.NationalQuota, 0).Minutes())*t.NationalMinutePrice +
+		math.Ceil(max(u.Mobile-t.MobileQuota, 0).Minutes())*t.MobileMinutePrice
+}
+
+// A Forecast is the estimated usage at the end of the billing period.
+type Forecast struct {
+	// Model is the name of the forecast model.
+	Model string
+	// Window is the length of the time frame on which the forecast is based.
+	Window time.Duration
+	// Estimate is the point estimate of the usage at the end of the period.
+	Estimate easybell.Usage
+	// Low and High delimit the range of the estimate at the confidence Level.
+	Low   easybell.Usage
+	High  easybell.Usage
+	Level float64
+	// NationalExhausted and MobileExhausted are the days on which the respective quota is projected to be exhausted.
+	// The zero value indicates that the quota is not exhausted within the period.
+	NationalExhausted time.Time
+	MobileExhausted   time.Time
+}
+
+// Status classifies how near a usage is to its quota.
+type Status int
+
+// These constants identify the known statuses.
+const (
+	// StatusGood indicates that the usage is well below the quota.
+	StatusGood Status = iota
+	// StatusWarning indicates that the usage is near the quota.
+	StatusWarning
+	// StatusAttention indicates that the usage is significantly over the quota.
+	StatusAttention
+)
+
+// Statuses contains the names of all statuses, indexed by status.
+var Statuses = []string{"good", "warning", "attention"}
+
+// String returns the name of s.
+func (s Status) String() string {
+	if s < 0 || int(s) >= len(Statuses) {
+		return fmt.Sprintf("Status(%d)", int(s))
+	}
+	return Statuses[s]
+}
+
+// ParseStatus returns the status with the specified name.
+func ParseStatus(name string) (Status, error) {
+	for i, s := range Statuses {
+		if s == name {
+			return Status(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown status %q, must be one of %s", name, strings.Join(Statuses, ", "))
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (s Status) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (s *Status) UnmarshalText(text []byte) (err error) {
+	*s, err = ParseStatus(string(text))
+	return err
+}
+
+// QuotaStatus classifies d depending on how near it is to its quota.
+// A usage of up to 90% of the quota is good, a usage of up to 110% of the quota is a warning.
+// Without a quota any usage is significant.
+func QuotaStatus(d, quota time.Duration) Status {
+	switch {
+	case d == 0 || d <= quota*9/10:
+		return StatusGood
+	case d <= quota*11/10:
+		return StatusWarning
+	default:
+		return StatusAttention
+	}
+}
+
+// Status returns the most severe status of the expected usage in r.
+// Any international usage is considered significant because it is not included in any quota.
+func (r *Report) Status() Status {
+	if r.Kind == KindSummary {
+		status := StatusGood
+		for _, a := range r.Accounts {
+			status = max(status, a.Status())
+		}
+		return status
+	}
+	u := r.ExpectedUsage()
+	return max(
+		QuotaStatus(u.National, r.Tariff.NationalQuota),
+		QuotaStatus(u.Mobile, r.Tariff.MobileQuota),
+		QuotaStatus(u.Other, 0),
+	)
+}
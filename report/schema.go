@@ -0,0 +1,245 @@
+package report
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/lmr-hh/easybell-billing-info/easybell"
+)
+
+// SchemaVersion is the version of the [Document] schema.
+// It is incremented on every incompatible change of the schema,
+// i.e. when a field is removed or renamed or its type or meaning changes.
+// New fields may be added within a version, so consumers must ignore unknown fields.
+//
+// Fields added to version 1:
+//   - status: the most severe status of the expected usage.
+//   - alerts: the thresholds that triggered a report of the alert command.
+const SchemaVersion = 1
+
+// A Document is the stable, serializable representation of a [Report].
+// Durations are represented in seconds, prices and costs in Euro.
+type Document struct {
+	Version int            `json:"version" yaml:"version"`
+	Kind    Kind           `json:"kind" yaml:"kind"`
+	Account string         `json:"account,omitempty" yaml:"account,omitempty"`
+	Period  DocumentPeriod `json:"period" yaml:"period"`
+	// Status is the most severe status of the expected usage.
+	Status Status `json:"status" yaml:"status"`
+	// Alerts contains the thresholds whose crossing triggered the report.
+	// It is only set for reports that are sent by the alert command.
+	Alerts   []DocumentAlert   `json:"alerts,omitempty" yaml:"alerts,omitempty"`
+	Usage    *DocumentUsage    `json:"usage,omitempty" yaml:"usage,omitempty"`
+	Tariff   *DocumentTariff   `json:"tariff,omitempty" yaml:"tariff,omitempty"`
+	Forecast *DocumentForecast `json:"forecast,omitempty" yaml:"forecast,omitempty"`
+	// Cost is the additional cost in the period.
+	// For reports of the current period it is the cost of the estimated usage.
+	Cost     float64    `json:"cost" yaml:"cost"`
+	Currency string     `json:"currency" yaml:"currency"`
+	Accounts []Document `json:"accounts,omitempty" yaml:"accounts,omitempty"`
+}
+
+// DocumentPeriod is a billing period.
+// The period includes Start and excludes End.
+type DocumentPeriod struct {
+	Start time.Time `json:"start" yaml:"start"`
+	End   time.Time `json:"end" yaml:"end"`
+}
+
+// DocumentAlert is a threshold that has been crossed.
+// The Threshold and the Value are percentages of a quota or amounts in Euro, depending on the Kind.
+type DocumentAlert struct {
+	Kind      string  `json:"kind" yaml:"kind"`
+	Threshold float64 `json:"threshold" yaml:"threshold"`
+	Value     float64 `json:"value" yaml:"value"`
+}
+
+// DocumentUsage is the usage per kind of call.
+type DocumentUsage struct {
+	NationalSeconds      float64 `json:"national_seconds" yaml:"national_seconds"`
+	MobileSeconds        float64 `json:"mobile_seconds" yaml:"mobile_seconds"`
+	InternationalSeconds float64 `json:"international_seconds" yaml:"international_seconds"`
+}
+
+// DocumentCall is a single call of the call log.
+// The Account is omitted if only a single account is configured.
+type DocumentCall struct {
+	Account         string    `json:"account,omitempty" yaml:"account,omitempty"`
+	ID              string    `json:"id" yaml:"id"`
+	Time            time.Time `json:"time" yaml:"time"`
+	DurationSeconds float64   `json:"duration_seconds" yaml:"duration_seconds"`
+	Number          string    `json:"number" yaml:"number"`
+	Partner         string    `json:"partner" yaml:"partner"`
+	Direction       string    `json:"direction" yaml:"direction"`
+	Type            string    `json:"type" yaml:"type"`
+	Kind            string    `json:"kind" yaml:"kind"`
+	Status          string    `json:"status" yaml:"status"`
+}
+
+// NewDocumentCall returns the serializable representation of the call e of account.
+func NewDocumentCall(account string, e *easybell.CallLogEntry) DocumentCall {
+	return DocumentCall{
+		Account:         account,
+		ID:              e.ID,
+		Time:            e.Time,
+		DurationSeconds: e.Duration.Seconds(),
+		Number:          e.Number,
+		Partner:         e.Partner,
+		Direction:       e.Direction,
+		Type:            e.CallType,
+		Kind:            e.Kind,
+		Status:          e.Status,
+	}
+}
+
+// DocumentTariff contains the included quotas and the prices of additional minutes.
+type DocumentTariff struct {
+	NationalQuotaSeconds float64 `json:"national_quota_seconds" yaml:"national_quota_seconds"`
+	MobileQuotaSeconds   float64 `json:"mobile_quota_seconds" yaml:"mobile_quota_seconds"`
+	NationalMinutePrice  float64 `json:"national_minute_price" yaml:"national_minute_price"`
+	MobileMinutePrice    float64 `json:"mobile_minute_price" yaml:"mobile_minute_price"`
+}
+
+// DocumentForecast is the forecast to the end of the period.
+// The exhaustion dates are omitted if the respective quota is not exhausted within the period.
+type DocumentForecast struct {
+	Model             string        `json:"model" yaml:"model"`
+	WindowSeconds     float64       `json:"window_seconds" yaml:"window_seconds"`
+	Estimate          DocumentUsage `json:"estimate" yaml:"estimate"`
+	Low               DocumentUsage `json:"low" yaml:"low"`
+	High              DocumentUsage `json:"high" yaml:"high"`
+	Level             float64       `json:"level" yaml:"level"`
+	NationalExhausted *time.Time    `json:"national_exhausted,omitempty" yaml:"national_exhausted,omitempty"`
+	MobileExhausted   *time.Time    `json:"mobile_exhausted,omitempty" yaml:"mobile_exhausted,omitempty"`
+}
+
+// Document returns the serializable representation of r.
+func (r *Report) Document() Document {
+	d := Document{
+		Version:  SchemaVersion,
+		Kind:     r.Kind,
+		Account:  r.Account,
+		Period:   DocumentPeriod{r.Start, r.End},
+		Status:   r.Status(),
+		Cost:     r.Cost(),
+		Currency: "EUR",
+	}
+	for _, a := range r.Alerts {
+		d.Alerts = append(d.Alerts, DocumentAlert(a))
+	}
+	if r.Kind == KindSummary {
+		for _, a := range r.Accounts {
+			d.Accounts = append(d.Accounts, a.Document())
+		}
+		return d
+	}
+	usage := NewDocumentUsage(r.Usage)
+	d.Usage = &usage
+	d.Tariff = &DocumentTariff{
+		NationalQuotaSeconds: r.Tariff.NationalQuota.Seconds(),
+		MobileQuotaSeconds:   r.Tariff.MobileQuota.Seconds(),
+		NationalMinutePrice:  r.Tariff.NationalMinutePrice,
+		MobileMinutePrice:    r.Tariff.MobileMinutePrice,
+	}
+	if f := r.Forecast; f != nil {
+		d.Forecast = &DocumentForecast{
+			Model:             f.Model,
+			WindowSeconds:     f.Window.Seconds(),
+			Estimate:          NewDocumentUsage(f.Estimate),
+			Low:               NewDocumentUsage(f.Low),
+			High:              NewDocumentUsage(f.High),
+			Level:             f.Level,
+			NationalExhausted: optionalTime(f.NationalExhausted),
+			MobileExhausted:   optionalTime(f.MobileExhausted),
+		}
+	}
+	return d
+}
+
+// Report returns the report that is represented by d.
+// The status of d is ignored because it is derived from the usage.
+func (d Document) Report() (*Report, error) {
+	if d.Version != SchemaVersion {
+		return nil, fmt.Errorf("unsupported schema version %d", d.Version)
+	}
+	r := &Report{Kind: d.Kind, Account: d.Account, Start: d.Period.Start, End: d.Period.End}
+	for _, a := range d.Alerts {
+		r.Alerts = append(r.Alerts, Alert(a))
+	}
+	switch d.Kind {
+	case KindSummary:
+		for _, a := range d.Accounts {
+			account, err := a.Report()
+			if err != nil {
+				return nil, err
+			}
+			r.Accounts = append(r.Accounts, account)
+		}
+		return r, nil
+	case KindPrevious, KindCurrent:
+	default:
+		return nil, fmt.Errorf("unknown kind of report %q", d.Kind)
+	}
+	if d.Usage == nil || d.Tariff == nil {
+		return nil, errors.New("missing usage or tariff")
+	}
+	r.Usage = d.Usage.usage()
+	r.Tariff = Tariff{
+		NationalQuota:       seconds(d.Tariff.NationalQuotaSeconds),
+		MobileQuota:         seconds(d.Tariff.MobileQuotaSeconds),
+		NationalMinutePrice: d.Tariff.NationalMinutePrice,
+		MobileMinutePrice:   d.Tariff.MobileMinutePrice,
+	}
+	if f := d.Forecast; f != nil {
+		r.Forecast = &Forecast{
+			Model:    f.Model,
+			Window:   seconds(f.WindowSeconds),
+			Estimate: f.Estimate.usage(),
+			Low:      f.Low.usage(),
+			High:     f.High.usage(),
+			Level:    f.Level,
+		}
+		if f.NationalExhausted != nil {
+			r.Forecast.NationalExhausted = *f.NationalExhausted
+		}
+		if f.MobileExhausted != nil {
+			r.Forecast.MobileExhausted = *f.MobileExhausted
+		}
+	} else if d.Kind == KindCurrent {
+		return nil, errors.New("missing forecast")
+	}
+	return r, nil
+}
+
+// NewDocumentUsage returns the serializable representation of u.
+func NewDocumentUsage(u easybell.Usage) DocumentUsage {
+	return DocumentUsage{
+		NationalSeconds:      u.National.Seconds(),
+		MobileSeconds:        u.Mobile.Seconds(),
+		InternationalSeconds: u.Other.Seconds(),
+	}
+}
+
+// optionalTime returns nil for the zero time and a pointer to t otherwise.
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// usage returns the usage that is represented by u.
+func (u DocumentUsage) usage() easybell.Usage {
+	return easybell.Usage{
+		National: seconds(u.NationalSeconds),
+		Mobile:   seconds(u.MobileSeconds),
+		Other:    seconds(u.InternationalSeconds),
+	}
+}
+
+// seconds returns the duration of s seconds.
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Round(s * float64(time.Second)))
+}
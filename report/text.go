@@ -0,0 +1,135 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/lmr-hh/easybell-billing-info/easybell"
+	"github.com/lmr-hh/easybell-billing-info/i18n"
+)
+
+// textWriter formats text in a locale to an io.Writer and records the first error.
+type textWriter struct {
+	w   io.Writer
+	l   *i18n.Locale
+	err error
+}
+
+func (t *textWriter) printf(format string, args ...any) {
+	if t.err == nil {
+		_, t.err = fmt.Fprintf(t.w, format, args...)
+	}
+}
+
+// WriteText writes r as plain text in the language of l to w.
+// This is the format of the command line output.
+func WriteText(w io.Writer, r *Report, l *i18n.Locale) error {
+	t := &textWriter{w: w, l: l}
+	switch r.Kind {
+	case KindCurrent:
+		t.current(r)
+	case KindSummary:
+		t.summary(r)
+	default:
+		t.previous(r)
+	}
+	return t.err
+}
+
+// previous writes a report of a completed billing period.
+func (t *textWriter) previous(r *Report) {
+	t.printf("%s\n\n", t.l.T("text.title", r.Name(t.l)))
+	t.alerts(r)
+	t.usage(r.Usage, r.Tariff)
+	t.printf("\n")
+}
+
+// current writes a report of the current billing period including its forecast.
+func (t *textWriter) current(r *Report) {
+	f := r.Forecast
+	t.printf("%s\n\n", t.l.T("text.title", r.Name(t.l)))
+	t.alerts(r)
+	t.printf("%s\n", t.l.T("text.current"))
+	t.usage(r.Usage, r.Tariff)
+	t.printf("\n%s\n", t.l.T("text.estimate"))
+	t.usage(f.Estimate, r.Tariff)
+	t.printf("\n%s\n", t.l.T("text.range", t.l.Number(f.Level*100, 0)))
+	t.printf("  %s%s – %s\n", t.label("text.national", 15), FormatDuration(f.Low.National), FormatDuration(f.High.National))
+	t.printf("  %s%s – %s\n", t.label("text.mobile", 15), FormatDuration(f.Low.Mobile), FormatDuration(f.High.Mobile))
+	t.printf("  %s%s – %s\n", t.label("text.international", 15), FormatDuration(f.Low.Other), FormatDuration(f.High.Other))
+	if !f.NationalExhausted.IsZero() || !f.MobileExhausted.IsZero() {
+		t.printf("\n")
+	}
+	if !f.NationalExhausted.IsZero() {
+		t.printf("%s\n", t.l.T("text.exhausted.national", t.l.ShortDate(f.NationalExhausted)))
+	}
+	if !f.MobileExhausted.IsZero() {
+		t.printf("%s\n", t.l.T("text.exhausted.mobile", t.l.ShortDate(f.MobileExhausted)))
+	}
+	t.printf("\n%s\n\n", t.l.T("text.basis", f.Model, t.l.Number(f.Window.Hours()/24, 1)))
+}
+
+// summary writes a combined report of several accounts as a table.
+func (t *textWriter) summary(r *Report) {
+	if len(r.Accounts) > 0 && r.Accounts[0].Kind == KindCurrent {
+		t.printf("%s\n\n", t.l.T("text.forecast"))
+	}
+	t.printf("%s\n\n", t.l.T("text.summary"))
+	if t.err != nil {
+		return
+	}
+	w := tabwriter.NewWriter(t.w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t\n", t.l.T("account"), t.l.T("text.national"), t.l.T("text.mobile"), t.l.T("text.international"), t.l.T("text.cost"))
+	for _, a := range r.Accounts {
+		u := a.ExpectedUsage()
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t\n", a.Account, FormatDuration(u.National), FormatDuration(u.Mobile), FormatDuration(u.Other), t.l.Cost(a.Cost()))
+	}
+	_, _ = fmt.Fprintf(w, "%s\t\t\t\t%s\t\n", t.l.T("text.total"), t.l.Cost(r.Cost()))
+	t.err = w.Flush()
+}
+
+// alerts writes the thresholds whose crossing triggered r.
+func (t *textWriter) alerts(r *Report) {
+	for _, a := range r.Alerts {
+		t.printf("%s\n", a.Text(t.l))
+	}
+	if len(r.Alerts) > 0 {
+		t.printf("\n")
+	}
+}
+
+// usage writes u with the quotas of t.
+func (t *textWriter) usage(u easybell.Usage, tariff Tariff) {
+	t.printf("  %s%06s / %04.0f:00 (%s %%)\n", t.label("text.national", 15), FormatDuration(u.National), tariff.NationalQuota.Minutes(), t.l.Number(float64(u.National)/float64(tariff.NationalQuota)*100, 2))
+	t.printf("  %s%5s /  %03.0f:00 (%s %%)\n", t.label("text.mobile", 16), FormatDuration(u.Mobile), tariff.MobileQuota.Minutes(), t.l.Number(float64(u.Mobile)/float64(tariff.MobileQuota)*100, 2))
+	t.printf("  %s%5s /   00:00\n", t.label("text.international", 16), FormatDuration(u.Other))
+}
+
+// label returns the message with the specified key followed by a colon and padded to width.
+func (t *textWriter) label(key string, width int) string {
+	return fmt.Sprintf("%-*s", width, t.l.T(key)+":")
+}
+
+// Name returns a name for r consisting of the period and the account name.
+func (r *Report) Name(l *i18n.Locale) string {
+	if r.Account == "" {
+		return r.PeriodName(l)
+	}
+	return fmt.Sprintf("%s (%s)", r.PeriodName(l), r.Account)
+}
+
+// PeriodName returns a name for the period of r.
+// Calendar months are named by the month, other periods by their first and last day.
+func (r *Report) PeriodName(l *i18n.Locale) string {
+	if r.IsCalendarMonth() {
+		return fmt.Sprintf("%s %d", l.Month(r.Start.Month()), r.Start.Year())
+	}
+	return l.T("period.range", l.Date(r.Start), l.Date(r.LastDay()))
+}
+
+// FormatDuration formats d in a user-friendly way as mm:ss.
+func FormatDuration(d time.Duration) string {
+	return fmt.Sprintf("%02.0f:%02.0f", d.Truncate(time.Minute).Minutes(), (d - d.Truncate(time.Minute)).Seconds())
+}